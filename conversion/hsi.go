@@ -0,0 +1,78 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"math"
+)
+
+// RGBAToHSI transforms a color in the RGBA color space into the HSI equivalent.
+// The formulas used can be found on Wikipedia.
+// https://en.wikipedia.org/wiki/HSL_and_HSV#Hue_and_chroma
+// The Hue will be in [0,360), while Saturation and Intensity will be in [0,100].
+func RGBAToHSI(rValue, gValue, bValue, aValue uint32) (h, s, i float64) {
+
+	r, g, b, ok := unpremultiply(rValue, gValue, bValue, aValue)
+	if !ok {
+		return h, s, i
+	}
+
+	//intensity
+	i = (r + g + b) / 3
+
+	// Greyscale, only I can be != 0
+	minValue := math.Min(r, math.Min(g, b))
+	if minValue == math.Max(r, math.Max(g, b)) {
+		return 0, 0, i * 100
+	}
+
+	//hue
+	h = math.Atan2(math.Sqrt(3)*(g-b), 2*r-g-b) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+
+	//saturation
+	s = 1 - minValue/i
+
+	return h, s * 100, i * 100
+
+}
+
+// HSIToRGBA transforms a color in the HSI color space into the RGBA equivalent.
+// The Hue is expected in degrees and will be wrapped into [0,360), while
+// Saturation and Intensity are expected in [0,100] and will be clamped to it.
+// Since not every HSI color can be represented in RGB, the components of the
+// result are clamped as well.
+// The result is fully opaque and uses the same 16-bit range of color.Color.
+func HSIToRGBA(h, s, i float64) (r, g, b, a uint32) {
+
+	s = clamp(s, 0, 100) / 100
+	i = clamp(i, 0, 100) / 100
+
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	// The formulas are the same for each 120° sector,
+	// only the components they refer to are rotated.
+	sector := int(h / 120)
+	angle := (h - float64(sector)*120) * math.Pi / 180
+
+	low := i * (1 - s)
+	high := i * (1 + s*math.Cos(angle)/math.Cos(math.Pi/3-angle))
+	middle := 3*i - low - high
+
+	switch sector {
+	case 0:
+		return premultiply(high, middle, low)
+	case 1:
+		return premultiply(low, high, middle)
+	default:
+		return premultiply(middle, low, high)
+	}
+
+}
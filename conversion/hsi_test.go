@@ -0,0 +1,25 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"testing"
+)
+
+func TestRGBAToHSI(t *testing.T) {
+
+	testConversion(t, "RGBAToHSI", []colorTest{
+		{name: "#ff0000", c1: 0, c2: 100, c3: 100.0 / 3},
+		{name: "#ffff00", c1: 60, c2: 100, c3: 200.0 / 3},
+		{name: "#00ffff", c1: 180, c2: 100, c3: 200.0 / 3},
+		{name: "#808080", c1: 0, c2: 0, c3: 100 * 128.0 / 255},
+		{name: "#000000", c1: 0, c2: 0, c3: 0},
+	}, 1e-9, RGBAToHSI)
+
+}
+
+func TestHSIToRGBA(t *testing.T) {
+	testRoundTrip(t, "HSI", RGBAToHSI, HSIToRGBA)
+}
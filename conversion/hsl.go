@@ -0,0 +1,58 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"math"
+)
+
+// RGBAToHSL transforms a color in the RGBA color space into the HSL equivalent.
+// The formulas used can be found on Wikipedia.
+// https://en.wikipedia.org/wiki/HSL_and_HSV#Color_conversion_formulae
+// The Hue will be in [0,360), while Saturation and Lightness will be in [0,100].
+func RGBAToHSL(rValue, gValue, bValue, aValue uint32) (h, s, l float64) {
+
+	r, g, b, ok := unpremultiply(rValue, gValue, bValue, aValue)
+	if !ok {
+		return h, s, l
+	}
+
+	maxValue := math.Max(r, math.Max(g, b))
+	minValue := math.Min(r, math.Min(g, b))
+	delta := maxValue - minValue
+
+	//lightness
+	l = (maxValue + minValue) / 2
+
+	// Greyscale, only L can be != 0
+	if delta == 0 {
+		return 0, 0, l * 100
+	}
+
+	h = hue(r, g, b, maxValue, delta)
+
+	//saturation
+	s = delta / (1 - math.Abs(2*l-1))
+
+	return h, s * 100, l * 100
+
+}
+
+// HSLToRGBA transforms a color in the HSL color space into the RGBA equivalent.
+// The Hue is expected in degrees and will be wrapped into [0,360), while
+// Saturation and Lightness are expected in [0,100] and will be clamped to it.
+// The result is fully opaque and uses the same 16-bit range of color.Color.
+func HSLToRGBA(h, s, l float64) (r, g, b, a uint32) {
+
+	s = clamp(s, 0, 100) / 100
+	l = clamp(l, 0, 100) / 100
+
+	chroma := (1 - math.Abs(2*l-1)) * s
+	red, green, blue := fromHueAndChroma(h, chroma)
+
+	m := l - chroma/2
+	return premultiply(red+m, green+m, blue+m)
+
+}
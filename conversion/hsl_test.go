@@ -0,0 +1,26 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"testing"
+)
+
+func TestRGBAToHSL(t *testing.T) {
+
+	testConversion(t, "RGBAToHSL", []colorTest{
+		{name: "#ff0000", c1: 0, c2: 100, c3: 50},
+		{name: "#00ff00", c1: 120, c2: 100, c3: 50},
+		{name: "#000080", c1: 240, c2: 100, c3: 100 * 64.0 / 255},
+		{name: "#ffffff", c1: 0, c2: 0, c3: 100},
+		{name: "#000000", c1: 0, c2: 0, c3: 0},
+		{name: "#bada55", c1: 60 * ((85.0-186)/(218-85) + 2), c2: 100 * (218.0 - 85) / (510 - 218 - 85), c3: 100 * (218.0 + 85) / 510},
+	}, 1e-9, RGBAToHSL)
+
+}
+
+func TestHSLToRGBA(t *testing.T) {
+	testRoundTrip(t, "HSL", RGBAToHSL, HSLToRGBA)
+}
@@ -3,7 +3,8 @@
 // license that can be found in the LICENSE file.
 
 // Package conversion provides a way to convert a color from the
-// RGBA color space to HSV.
+// RGBA color space to HSV and to other color spaces, such as HSL,
+// HSI, CIE XYZ, CIELAB, CIELUV, LCh and YCbCr, and back.
 package conversion
 
 import (
@@ -13,21 +14,25 @@ import (
 // RGBAToHSV transforms a color in the RGBA color space into the HSV equivalent.
 // The formulas used can be found on Wikipedia.
 // https://en.wikipedia.org/wiki/HSL_and_HSV#Color_conversion_formulae
+// The Hue is rounded to the closest degree, while Saturation and Value
+// are rounded to the closest percentage point.
 func RGBAToHSV(rValue, gValue, bValue, aValue uint32) (h, s, v float64) {
 
-	// The RGBA color components are scaled by the Alpha value, as per:
-	// https://golang.org/src/image/color/color.go?s=2394:2435#L21
-	// Since we need RGB values in the [0-1] range, we need to divide
-	// them by A, making sure it's not 0.
-	if aValue == 0 {
+	h, s, v = RGBAToHSVExact(rValue, gValue, bValue, aValue)
+	return math.Round(h), math.Round(s), math.Round(v)
+
+}
+
+// RGBAToHSVExact transforms a color in the RGBA color space into the HSV
+// equivalent, without rounding the result.
+// The Hue will be in [0,360), while Saturation and Value will be in [0,100].
+func RGBAToHSVExact(rValue, gValue, bValue, aValue uint32) (h, s, v float64) {
+
+	r, g, b, ok := unpremultiply(rValue, gValue, bValue, aValue)
+	if !ok {
 		return h, s, v
 	}
 
-	a := float64(aValue)
-	r := float64(rValue) / a
-	g := float64(gValue) / a
-	b := float64(bValue) / a
-
 	maxValue := math.Max(r, math.Max(g, b))
 
 	// They're all 0s
@@ -40,7 +45,58 @@ func RGBAToHSV(rValue, gValue, bValue, aValue uint32) (h, s, v float64) {
 
 	// Greyscale, only V can be != 0
 	if delta == 0 {
-		return 0, 0, math.Round(maxValue * 100)
+		return 0, 0, maxValue * 100
+	}
+
+	//hue
+	h = hue(r, g, b, maxValue, delta)
+
+	//saturation
+	s = 100 * delta / maxValue
+
+	//value
+	v = maxValue * 100
+	return h, s, v
+
+}
+
+// RGBAToHSVExact32 is like RGBAToHSVExact, but it works with float32 values,
+// trading some precision for speed.
+func RGBAToHSVExact32(rValue, gValue, bValue, aValue uint32) (h, s, v float32) {
+
+	if aValue == 0 {
+		return h, s, v
+	}
+
+	a := float32(aValue)
+	r := float32(rValue) / a
+	g := float32(gValue) / a
+	b := float32(bValue) / a
+
+	maxValue, minValue := r, r
+	if g > maxValue {
+		maxValue = g
+	}
+	if b > maxValue {
+		maxValue = b
+	}
+	if g < minValue {
+		minValue = g
+	}
+	if b < minValue {
+		minValue = b
+	}
+
+	// They're all 0s
+	if maxValue == 0 {
+		return 0, 0, 0
+	}
+
+	delta := maxValue - minValue
+
+	// Greyscale, only V can be != 0
+	if delta == 0 {
+		return 0, 0, maxValue * 100
 	}
 
 	//hue
@@ -57,13 +113,54 @@ func RGBAToHSV(rValue, gValue, bValue, aValue uint32) (h, s, v float64) {
 		h += 360
 	}
 
-	h = math.Round(h)
-
 	//saturation
-	s = math.Round(100 * delta / maxValue)
+	s = 100 * delta / maxValue
 
 	//value
-	v = math.Round(maxValue * 100)
+	v = maxValue * 100
 	return h, s, v
 
 }
+
+// HSVToRGBA transforms a color in the HSV color space into the RGBA equivalent.
+// The Hue is expected in degrees and will be wrapped into [0,360), while
+// Saturation and Value are expected in [0,100] and will be clamped to it.
+// The result is fully opaque and uses the same 16-bit range of color.Color.
+func HSVToRGBA(h, s, v float64) (r, g, b, a uint32) {
+
+	s = clamp(s, 0, 100) / 100
+	v = clamp(v, 0, 100) / 100
+
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	// Find the sector of the color wheel and the position within it.
+	sector := h / 60
+	i := math.Floor(sector)
+	f := sector - i
+
+	p := v * (1 - s)
+	q := v * (1 - s*f)
+	t := v * (1 - s*(1-f))
+
+	var red, green, blue float64
+	switch int(i) {
+	case 0:
+		red, green, blue = v, t, p
+	case 1:
+		red, green, blue = q, v, p
+	case 2:
+		red, green, blue = p, v, t
+	case 3:
+		red, green, blue = p, q, v
+	case 4:
+		red, green, blue = t, p, v
+	default:
+		red, green, blue = v, p, q
+	}
+
+	return premultiply(red, green, blue)
+
+}
@@ -7,6 +7,7 @@ package conversion
 import (
 	"errors"
 	"image/color"
+	"math"
 	"testing"
 )
 
@@ -146,6 +147,137 @@ func BenchmarkRGBAToHSV(b *testing.B) {
 
 }
 
+func TestRGBAToHSVExact(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		wantH float64
+		wantS float64
+		wantV float64
+	}{
+		{name: "#ff0000", wantH: 0, wantS: 100, wantV: 100},
+		{name: "#00ff00", wantH: 120, wantS: 100, wantV: 100},
+		{name: "#0000ff", wantH: 240, wantS: 100, wantV: 100},
+		{name: "#000000", wantH: 0, wantS: 0, wantV: 0},
+		{name: "#ffffff", wantH: 0, wantS: 0, wantV: 100},
+		{name: "#808080", wantH: 0, wantS: 0, wantV: 100 * 128.0 / 255},
+		{name: "#bada55", wantH: 60 * ((85.0-186)/(218-85) + 2), wantS: 100 * (218.0 - 85) / 218, wantV: 100 * 218.0 / 255},
+		{name: "#ff0001", wantH: 360 - 60.0/255, wantS: 100, wantV: 100},
+	}
+
+	for _, tt := range tests {
+
+		testColor, err := ParseHexColorFast(tt.name)
+		if err != nil {
+			t.Errorf("RGBAToHSVExact() unable to parse color %s", tt.name)
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			gotH, gotS, gotV := RGBAToHSVExact(testColor.RGBA())
+			if math.Abs(gotH-tt.wantH) > 1e-9 || math.Abs(gotS-tt.wantS) > 1e-9 || math.Abs(gotV-tt.wantV) > 1e-9 {
+				t.Errorf("RGBAToHSVExact() Color %s\ngot = %v %v %v, want %v %v %v", tt.name, gotH, gotS, gotV, tt.wantH, tt.wantS, tt.wantV)
+			}
+
+		})
+
+	}
+
+}
+
+func TestRGBAToHSVExact32(t *testing.T) {
+
+	for r := 0; r < 256; r += 15 {
+		for g := 0; g < 256; g += 15 {
+			for b := 0; b < 256; b += 15 {
+
+				c := color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
+				h, s, v := RGBAToHSVExact(c.RGBA())
+				h32, s32, v32 := RGBAToHSVExact32(c.RGBA())
+
+				if math.Abs(h-float64(h32)) > 1e-3 || math.Abs(s-float64(s32)) > 1e-3 || math.Abs(v-float64(v32)) > 1e-3 {
+					t.Errorf("RGBAToHSVExact32() Color %v\ngot = %v %v %v, want %v %v %v", c, h32, s32, v32, h, s, v)
+				}
+
+			}
+		}
+	}
+
+}
+
+func TestHSVToRGBA(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		h, s, v float64
+		want    string
+	}{
+		{name: "Red", h: 0, s: 100, v: 100, want: "#ff0000"},
+		{name: "Red, wrapped", h: 360, s: 100, v: 100, want: "#ff0000"},
+		{name: "Green", h: 120, s: 100, v: 100, want: "#00ff00"},
+		{name: "Blue, negative hue", h: -120, s: 100, v: 100, want: "#0000ff"},
+		{name: "White", h: 42, s: 0, v: 100, want: "#ffffff"},
+		{name: "Black", h: 42, s: 100, v: 0, want: "#000000"},
+		{name: "Clamped", h: 0, s: 150, v: 150, want: "#ff0000"},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			want, err := ParseHexColorFast(tt.want)
+			if err != nil {
+				t.Fatalf("HSVToRGBA() unable to parse color %s", tt.want)
+			}
+
+			wantR, wantG, wantB, wantA := want.RGBA()
+			gotR, gotG, gotB, gotA := HSVToRGBA(tt.h, tt.s, tt.v)
+			if gotR != wantR || gotG != wantG || gotB != wantB || gotA != wantA {
+				t.Errorf("HSVToRGBA() = %v %v %v %v, want %v %v %v %v", gotR, gotG, gotB, gotA, wantR, wantG, wantB, wantA)
+			}
+
+		})
+
+	}
+
+}
+
+func TestHSVToRGBARoundTrip(t *testing.T) {
+
+	for r := 0; r < 256; r += 5 {
+		for g := 0; g < 256; g += 5 {
+			for b := 0; b < 256; b += 5 {
+
+				c := color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
+				wantR, wantG, wantB, wantA := c.RGBA()
+				gotR, gotG, gotB, gotA := HSVToRGBA(RGBAToHSVExact(c.RGBA()))
+
+				if gotR != wantR || gotG != wantG || gotB != wantB || gotA != wantA {
+					t.Errorf("HSVToRGBA(RGBAToHSVExact()) Color %v\ngot = %v %v %v %v", c, gotR, gotG, gotB, gotA)
+				}
+
+			}
+		}
+	}
+
+}
+
+func BenchmarkRGBAToHSVExact(b *testing.B) {
+
+	for i := 0; i < b.N; i++ {
+		RGBAToHSVExact(14906, 25700, 35980, 65535)
+	}
+
+}
+
+func BenchmarkRGBAToHSVExact32(b *testing.B) {
+
+	for i := 0; i < b.N; i++ {
+		RGBAToHSVExact32(14906, 25700, 35980, 65535)
+	}
+
+}
+
 // from https://stackoverflow.com/a/54200713
 var errInvalidFormat = errors.New("invalid format")
 
@@ -0,0 +1,140 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"math"
+)
+
+// Constants of the CIELAB and CIELUV transformations, as defined by the CIE standard.
+// http://www.brucelindbloom.com/index.html?LContinuity.html
+const (
+	labEpsilon = 216.0 / 24389
+	labKappa   = 24389.0 / 27
+)
+
+// RGBAToLab transforms a color in the RGBA color space, assumed to be sRGB,
+// into the CIELAB equivalent, under the D65 illuminant.
+// L will be in [0,100], while a and b are roughly in [-128,127].
+func RGBAToLab(rValue, gValue, bValue, aValue uint32) (l, a, b float64) {
+
+	l, a, b = XYZToLab(RGBAToXYZ(rValue, gValue, bValue, aValue))
+
+	// Greyscale, only L can be != 0, but rounding
+	// errors may leave a tiny amount of a and b.
+	if rValue == gValue && gValue == bValue {
+		return l, 0, 0
+	}
+
+	return l, a, b
+
+}
+
+// LabToRGBA transforms a color in the CIELAB color space, under the D65
+// illuminant, into the sRGB equivalent. Since not every CIELAB color can be
+// represented in sRGB, the components of the result are clamped.
+// The result is fully opaque and uses the same 16-bit range of color.Color.
+func LabToRGBA(l, a, b float64) (rValue, gValue, bValue, aValue uint32) {
+	return XYZToRGBA(LabToXYZ(l, a, b))
+}
+
+// XYZToLab transforms a color in the CIE 1931 XYZ color space into the CIELAB
+// equivalent, using the D65 reference white.
+// The formulas used can be found on Wikipedia.
+// https://en.wikipedia.org/wiki/CIELAB_color_space#From_CIEXYZ_to_CIELAB
+func XYZToLab(x, y, z float64) (l, a, b float64) {
+
+	fx := labF(x / whiteX)
+	fy := labF(y / whiteY)
+	fz := labF(z / whiteZ)
+
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+
+}
+
+// LabToXYZ transforms a color in the CIELAB color space into the CIE 1931 XYZ
+// equivalent, using the D65 reference white. It is the inverse of XYZToLab.
+func LabToXYZ(l, a, b float64) (x, y, z float64) {
+
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+
+	return whiteX * labFInverse(fx), whiteY * labFInverse(fy), whiteZ * labFInverse(fz)
+
+}
+
+// RGBAToLCh transforms a color in the RGBA color space, assumed to be sRGB,
+// into the cylindrical representation of CIELAB, LCh(ab).
+// L will be in [0,100], C will be non-negative and h will be in [0,360).
+func RGBAToLCh(rValue, gValue, bValue, aValue uint32) (l, c, h float64) {
+	return LabToLCh(RGBAToLab(rValue, gValue, bValue, aValue))
+}
+
+// LChToRGBA transforms a color in the LCh(ab) color space into the sRGB
+// equivalent. Since not every LCh color can be represented in sRGB, the
+// components of the result are clamped.
+// The result is fully opaque and uses the same 16-bit range of color.Color.
+func LChToRGBA(l, c, h float64) (r, g, b, a uint32) {
+	return LabToRGBA(LChToLab(l, c, h))
+}
+
+// LabToLCh transforms a color in the CIELAB color space into its cylindrical
+// representation, LCh(ab). The hue h will be in [0,360).
+func LabToLCh(l, a, b float64) (lightness, c, h float64) {
+	c, h = toPolar(a, b)
+	return l, c, h
+}
+
+// LChToLab transforms a color in the LCh(ab) color space into the CIELAB
+// equivalent. It is the inverse of LabToLCh.
+func LChToLab(l, c, h float64) (lightness, a, b float64) {
+	a, b = fromPolar(c, h)
+	return l, a, b
+}
+
+// labF is the non-linear function used to compute the CIELAB components.
+func labF(t float64) float64 {
+
+	if t > labEpsilon {
+		return math.Cbrt(t)
+	}
+
+	return (labKappa*t + 16) / 116
+
+}
+
+// labFInverse is the inverse of labF.
+func labFInverse(t float64) float64 {
+
+	if cube := t * t * t; cube > labEpsilon {
+		return cube
+	}
+
+	return (116*t - 16) / labKappa
+
+}
+
+// toPolar returns the magnitude and the angle, in degrees
+// in [0,360), of the vector with the given coordinates.
+func toPolar(x, y float64) (magnitude, angle float64) {
+
+	angle = math.Atan2(y, x) * 180 / math.Pi
+	if angle < 0 {
+		angle += 360
+	}
+
+	return math.Hypot(x, y), angle
+
+}
+
+// fromPolar returns the coordinates of the vector with
+// the given magnitude and angle, expressed in degrees.
+func fromPolar(magnitude, angle float64) (x, y float64) {
+
+	radians := angle * math.Pi / 180
+	return magnitude * math.Cos(radians), magnitude * math.Sin(radians)
+
+}
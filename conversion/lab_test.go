@@ -0,0 +1,55 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestRGBAToLab(t *testing.T) {
+
+	testConversion(t, "RGBAToLab", []colorTest{
+		{name: "#ffffff", c1: 100, c2: 0, c3: 0},
+		{name: "#ff0000", c1: 53.2408, c2: 80.0925, c3: 67.2032},
+		{name: "#0000ff", c1: 32.2970, c2: 79.1875, c3: -107.8602},
+		{name: "#000000", c1: 0, c2: 0, c3: 0},
+	}, 1e-3, RGBAToLab)
+
+}
+
+func TestLabToRGBA(t *testing.T) {
+	testRoundTrip(t, "Lab", RGBAToLab, LabToRGBA)
+}
+
+func TestRGBAToLCh(t *testing.T) {
+
+	testConversion(t, "RGBAToLCh", []colorTest{
+		{name: "#ff0000", c1: 53.2408, c2: 104.5518, c3: 39.9990},
+		{name: "#0000ff", c1: 32.2970, c2: 133.8076, c3: 306.2849},
+	}, 1e-3, RGBAToLCh)
+
+}
+
+func TestLChToRGBA(t *testing.T) {
+	testRoundTrip(t, "LCh", RGBAToLCh, LChToRGBA)
+}
+
+func TestRGBAToLabGreys(t *testing.T) {
+
+	for grey := 0; grey < 256; grey++ {
+
+		c := color.Gray{Y: uint8(grey)}
+		if _, a, b := RGBAToLab(c.RGBA()); a != 0 || b != 0 {
+			t.Errorf("RGBAToLab() Grey %d: a = %v, b = %v, want 0", grey, a, b)
+		}
+
+		if _, u, v := RGBAToLuv(c.RGBA()); u != 0 || v != 0 {
+			t.Errorf("RGBAToLuv() Grey %d: u = %v, v = %v, want 0", grey, u, v)
+		}
+
+	}
+
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"math"
+)
+
+// RGBAToLuv transforms a color in the RGBA color space, assumed to be sRGB,
+// into the CIELUV equivalent, under the D65 illuminant.
+// L will be in [0,100], while u and v are roughly in [-100,100].
+func RGBAToLuv(rValue, gValue, bValue, aValue uint32) (l, u, v float64) {
+
+	l, u, v = XYZToLuv(RGBAToXYZ(rValue, gValue, bValue, aValue))
+
+	// Greyscale, only L can be != 0, but rounding
+	// errors may leave a tiny amount of u and v.
+	if rValue == gValue && gValue == bValue {
+		return l, 0, 0
+	}
+
+	return l, u, v
+
+}
+
+// LuvToRGBA transforms a color in the CIELUV color space, under the D65
+// illuminant, into the sRGB equivalent. Since not every CIELUV color can be
+// represented in sRGB, the components of the result are clamped.
+// The result is fully opaque and uses the same 16-bit range of color.Color.
+func LuvToRGBA(l, u, v float64) (r, g, b, a uint32) {
+	return XYZToRGBA(LuvToXYZ(l, u, v))
+}
+
+// XYZToLuv transforms a color in the CIE 1931 XYZ color space into the CIELUV
+// equivalent, using the D65 reference white.
+// The formulas used can be found on Wikipedia.
+// https://en.wikipedia.org/wiki/CIELUV#The_forward_transformation
+func XYZToLuv(x, y, z float64) (l, u, v float64) {
+
+	// Black, the chromaticity is undefined.
+	denominator := x + 15*y + 3*z
+	if denominator == 0 {
+		return 0, 0, 0
+	}
+
+	whiteU, whiteV := chromaticity(whiteX, whiteY, whiteZ)
+	uPrime, vPrime := 4*x/denominator, 9*y/denominator
+
+	yRatio := y / whiteY
+	if yRatio > labEpsilon {
+		l = 116*math.Cbrt(yRatio) - 16
+	} else {
+		l = labKappa * yRatio
+	}
+
+	return l, 13 * l * (uPrime - whiteU), 13 * l * (vPrime - whiteV)
+
+}
+
+// LuvToXYZ transforms a color in the CIELUV color space into the CIE 1931 XYZ
+// equivalent, using the D65 reference white. It is the inverse of XYZToLuv.
+func LuvToXYZ(l, u, v float64) (x, y, z float64) {
+
+	if l <= 0 {
+		return 0, 0, 0
+	}
+
+	whiteU, whiteV := chromaticity(whiteX, whiteY, whiteZ)
+	uPrime := u/(13*l) + whiteU
+	vPrime := v/(13*l) + whiteV
+
+	if l > labKappa*labEpsilon {
+		cube := (l + 16) / 116
+		y = whiteY * cube * cube * cube
+	} else {
+		y = whiteY * l / labKappa
+	}
+
+	x = y * 9 * uPrime / (4 * vPrime)
+	z = y * (12 - 3*uPrime - 20*vPrime) / (4 * vPrime)
+
+	return x, y, z
+
+}
+
+// RGBAToLChuv transforms a color in the RGBA color space, assumed to be sRGB,
+// into the cylindrical representation of CIELUV, LCh(uv).
+// L will be in [0,100], C will be non-negative and h will be in [0,360).
+func RGBAToLChuv(rValue, gValue, bValue, aValue uint32) (l, c, h float64) {
+
+	l, u, v := RGBAToLuv(rValue, gValue, bValue, aValue)
+	c, h = toPolar(u, v)
+	return l, c, h
+
+}
+
+// LChuvToRGBA transforms a color in the LCh(uv) color space into the sRGB
+// equivalent. Since not every LCh(uv) color can be represented in sRGB, the
+// components of the result are clamped.
+// The result is fully opaque and uses the same 16-bit range of color.Color.
+func LChuvToRGBA(l, c, h float64) (r, g, b, a uint32) {
+
+	u, v := fromPolar(c, h)
+	return LuvToRGBA(l, u, v)
+
+}
+
+// chromaticity returns the u' and v' chromaticity coordinates of a color.
+func chromaticity(x, y, z float64) (uPrime, vPrime float64) {
+
+	denominator := x + 15*y + 3*z
+	return 4 * x / denominator, 9 * y / denominator
+
+}
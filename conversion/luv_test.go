@@ -0,0 +1,27 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"testing"
+)
+
+func TestRGBAToLuv(t *testing.T) {
+
+	testConversion(t, "RGBAToLuv", []colorTest{
+		{name: "#ffffff", c1: 100, c2: 0, c3: 0},
+		{name: "#ff0000", c1: 53.2408, c2: 175.0151, c3: 37.7564},
+		{name: "#000000", c1: 0, c2: 0, c3: 0},
+	}, 1e-3, RGBAToLuv)
+
+}
+
+func TestLuvToRGBA(t *testing.T) {
+	testRoundTrip(t, "Luv", RGBAToLuv, LuvToRGBA)
+}
+
+func TestLChuvToRGBA(t *testing.T) {
+	testRoundTrip(t, "LChuv", RGBAToLChuv, LChuvToRGBA)
+}
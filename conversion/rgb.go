@@ -0,0 +1,91 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"math"
+)
+
+// unpremultiply transforms the alpha-premultiplied RGBA components returned
+// by color.Color's RGBA method into RGB values in the [0-1] range.
+// The RGBA color components are scaled by the Alpha value, as per:
+// https://golang.org/src/image/color/color.go?s=2394:2435#L21
+// so we need to divide them by A. If A is 0, ok will be false.
+func unpremultiply(rValue, gValue, bValue, aValue uint32) (r, g, b float64, ok bool) {
+
+	if aValue == 0 {
+		return 0, 0, 0, false
+	}
+
+	a := float64(aValue)
+	return float64(rValue) / a, float64(gValue) / a, float64(bValue) / a, true
+
+}
+
+// premultiply transforms RGB values in the [0-1] range into the fully opaque
+// 16-bit RGBA components used by color.Color. Values out of range are clamped.
+func premultiply(r, g, b float64) (rValue, gValue, bValue, aValue uint32) {
+	return toUint16(r), toUint16(g), toUint16(b), 0xffff
+}
+
+// toUint16 clamps x to [0-1] and scales it to [0-0xffff].
+func toUint16(x float64) uint32 {
+	return uint32(math.Round(clamp(x, 0, 1) * 0xffff))
+}
+
+// hue returns the Hue, in degrees, of a color whose
+// maximum component is maxValue and whose chroma is delta.
+func hue(r, g, b, maxValue, delta float64) (h float64) {
+
+	switch maxValue {
+	case r:
+		h = 60 * ((g - b) / delta)
+	case g:
+		h = 60 * (((b - r) / delta) + 2)
+	case b:
+		h = 60 * (((r - g) / delta) + 4)
+	}
+
+	if h < 0 {
+		h += 360
+	}
+
+	return h
+
+}
+
+// fromHueAndChroma returns the RGB components of the color with the given
+// Hue and chroma, before adding the amount needed to match its lightness.
+func fromHueAndChroma(h, chroma float64) (r, g, b float64) {
+
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	sector := h / 60
+	x := chroma * (1 - math.Abs(math.Mod(sector, 2)-1))
+
+	switch int(sector) {
+	case 0:
+		return chroma, x, 0
+	case 1:
+		return x, chroma, 0
+	case 2:
+		return 0, chroma, x
+	case 3:
+		return 0, x, chroma
+	case 4:
+		return x, 0, chroma
+	default:
+		return chroma, 0, x
+	}
+
+}
+
+// clamp limits x to [minValue,maxValue].
+func clamp(x, minValue, maxValue float64) float64 {
+	return math.Max(minValue, math.Min(x, maxValue))
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// colorTest describes the expected conversion of a color into a three-component color space.
+type colorTest struct {
+	name       string
+	c1, c2, c3 float64
+}
+
+// testConversion checks that convert transforms each test color into the expected
+// components, with the given tolerance.
+func testConversion(t *testing.T, name string, tests []colorTest, tolerance float64, convert func(r, g, b, a uint32) (float64, float64, float64)) {
+
+	for _, tt := range tests {
+
+		testColor, err := ParseHexColorFast(tt.name)
+		if err != nil {
+			t.Fatalf("%s() unable to parse color %s", name, tt.name)
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			got1, got2, got3 := convert(testColor.RGBA())
+			if math.Abs(got1-tt.c1) > tolerance || math.Abs(got2-tt.c2) > tolerance || math.Abs(got3-tt.c3) > tolerance {
+				t.Errorf("%s() Color %s\ngot = %v %v %v, want %v %v %v", name, tt.name, got1, got2, got3, tt.c1, tt.c2, tt.c3)
+			}
+
+		})
+
+	}
+
+}
+
+// testRoundTrip checks that converting a sample of 8-bit colors with convert and
+// back with invert gives the original colors back.
+func testRoundTrip(t *testing.T, name string, convert func(r, g, b, a uint32) (float64, float64, float64), invert func(c1, c2, c3 float64) (r, g, b, a uint32)) {
+
+	for r := 0; r < 256; r += 15 {
+		for g := 0; g < 256; g += 15 {
+			for b := 0; b < 256; b += 15 {
+
+				c := color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
+				wantR, wantG, wantB, wantA := c.RGBA()
+				gotR, gotG, gotB, gotA := invert(convert(c.RGBA()))
+
+				if absDiff(gotR, wantR) > 1 || absDiff(gotG, wantG) > 1 || absDiff(gotB, wantB) > 1 || gotA != wantA {
+					t.Errorf("%s round trip Color %v\ngot = %v %v %v %v, want %v %v %v %v", name, c, gotR, gotG, gotB, gotA, wantR, wantG, wantB, wantA)
+				}
+
+			}
+		}
+	}
+
+}
+
+func absDiff(a, b uint32) uint32 {
+
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+
+}
+
+func TestUnpremultiply(t *testing.T) {
+
+	// Half-transparent red.
+	r, g, b, ok := unpremultiply(color.NRGBA{R: 255, A: 128}.RGBA())
+	if !ok || math.Abs(r-1) > 1e-9 || g != 0 || b != 0 {
+		t.Errorf("unpremultiply() = %v %v %v %v, want 1 0 0 true", r, g, b, ok)
+	}
+
+	if _, _, _, ok := unpremultiply(0, 0, 0, 0); ok {
+		t.Errorf("unpremultiply() of a transparent color should not be ok")
+	}
+
+}
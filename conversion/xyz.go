@@ -0,0 +1,78 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"math"
+)
+
+// The reference white of the D65 illuminant, used by sRGB,
+// with the Y component scaled to 100.
+const (
+	whiteX = 95.047
+	whiteY = 100.0
+	whiteZ = 108.883
+)
+
+// RGBAToXYZ transforms a color in the RGBA color space, assumed to be sRGB,
+// into the CIE 1931 XYZ equivalent, under the D65 illuminant.
+// The formulas used can be found on Wikipedia.
+// https://en.wikipedia.org/wiki/SRGB#The_reverse_transformation
+// The components are scaled so that the reference white has a Y of 100.
+func RGBAToXYZ(rValue, gValue, bValue, aValue uint32) (x, y, z float64) {
+
+	r, g, b, ok := unpremultiply(rValue, gValue, bValue, aValue)
+	if !ok {
+		return x, y, z
+	}
+
+	r, g, b = linearize(r), linearize(g), linearize(b)
+
+	x = 0.4124564*r + 0.3575761*g + 0.1804375*b
+	y = 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z = 0.0193339*r + 0.1191920*g + 0.9503041*b
+
+	return x * 100, y * 100, z * 100
+
+}
+
+// XYZToRGBA transforms a color in the CIE 1931 XYZ color space, under the
+// D65 illuminant and with the reference white having a Y of 100, into the
+// sRGB equivalent. Since not every XYZ color can be represented in sRGB, the
+// components of the result are clamped.
+// The result is fully opaque and uses the same 16-bit range of color.Color.
+func XYZToRGBA(x, y, z float64) (r, g, b, a uint32) {
+
+	x, y, z = x/100, y/100, z/100
+
+	red := 3.2404542*x - 1.5371385*y - 0.4985314*z
+	green := -0.9692660*x + 1.8760108*y + 0.0415560*z
+	blue := 0.0556434*x - 0.2040259*y + 1.0572252*z
+
+	return premultiply(delinearize(red), delinearize(green), delinearize(blue))
+
+}
+
+// linearize removes the sRGB gamma from a component in [0-1].
+func linearize(c float64) float64 {
+
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+
+	return math.Pow((c+0.055)/1.055, 2.4)
+
+}
+
+// delinearize applies the sRGB gamma to a linear component in [0-1].
+func delinearize(c float64) float64 {
+
+	if c <= 0.0031308 {
+		return c * 12.92
+	}
+
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+
+}
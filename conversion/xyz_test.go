@@ -0,0 +1,23 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"testing"
+)
+
+func TestRGBAToXYZ(t *testing.T) {
+
+	testConversion(t, "RGBAToXYZ", []colorTest{
+		{name: "#ffffff", c1: 95.047, c2: 100, c3: 108.883},
+		{name: "#ff0000", c1: 41.24564, c2: 21.26729, c3: 1.93339},
+		{name: "#000000", c1: 0, c2: 0, c3: 0},
+	}, 1e-3, RGBAToXYZ)
+
+}
+
+func TestXYZToRGBA(t *testing.T) {
+	testRoundTrip(t, "XYZ", RGBAToXYZ, XYZToRGBA)
+}
@@ -0,0 +1,44 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+// RGBAToYCbCr transforms a color in the RGBA color space into the YCbCr
+// equivalent, using the full-range ITU-R BT.601 coefficients adopted by JFIF
+// and by the image/color package. The components will be in [0,255], with
+// Cb and Cr centered on 128, but they won't be rounded.
+// Fully transparent colors are black, which is 0, 128, 128.
+func RGBAToYCbCr(rValue, gValue, bValue, aValue uint32) (y, cb, cr float64) {
+
+	r, g, b, ok := unpremultiply(rValue, gValue, bValue, aValue)
+	if !ok {
+		return 0, 128, 128
+	}
+
+	r, g, b = r*255, g*255, b*255
+
+	y = 0.299*r + 0.587*g + 0.114*b
+	cb = 128 - 0.168736*r - 0.331264*g + 0.5*b
+	cr = 128 + 0.5*r - 0.418688*g - 0.081312*b
+
+	return y, cb, cr
+
+}
+
+// YCbCrToRGBA transforms a color in the YCbCr color space, with components in
+// [0,255], into the RGBA equivalent. It is the inverse of RGBAToYCbCr.
+// Since not every YCbCr color can be represented in RGB, the components of
+// the result are clamped.
+// The result is fully opaque and uses the same 16-bit range of color.Color.
+func YCbCrToRGBA(y, cb, cr float64) (r, g, b, a uint32) {
+
+	cb, cr = cb-128, cr-128
+
+	red := y + 1.402*cr
+	green := y - 0.344136*cb - 0.714136*cr
+	blue := y + 1.772*cb
+
+	return premultiply(red/255, green/255, blue/255)
+
+}
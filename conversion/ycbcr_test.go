@@ -0,0 +1,41 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conversion
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestRGBAToYCbCr(t *testing.T) {
+
+	// The result must match the one of the standard library, once rounded.
+	for r := 0; r < 256; r += 15 {
+		for g := 0; g < 256; g += 15 {
+			for b := 0; b < 256; b += 15 {
+
+				c := color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
+				wantY, wantCb, wantCr := color.RGBToYCbCr(c.R, c.G, c.B)
+				gotY, gotCb, gotCr := RGBAToYCbCr(c.RGBA())
+
+				if math.Abs(gotY-float64(wantY)) > 1 || math.Abs(gotCb-float64(wantCb)) > 1 || math.Abs(gotCr-float64(wantCr)) > 1 {
+					t.Errorf("RGBAToYCbCr() Color %v\ngot = %v %v %v, want %v %v %v", c, gotY, gotCb, gotCr, wantY, wantCb, wantCr)
+				}
+
+			}
+		}
+	}
+
+	// Fully transparent colors are black, which has neutral chroma.
+	if y, cb, cr := RGBAToYCbCr(color.Transparent.RGBA()); y != 0 || cb != 128 || cr != 128 {
+		t.Errorf("RGBAToYCbCr() of a transparent color = %v %v %v, want 0 128 128", y, cb, cr)
+	}
+
+}
+
+func TestYCbCrToRGBA(t *testing.T) {
+	testRoundTrip(t, "YCbCr", RGBAToYCbCr, YCbCrToRGBA)
+}
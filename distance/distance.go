@@ -0,0 +1,252 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package distance provides methods to compare the color histograms
+// computed by the histogram package.
+// Every method normalizes its inputs so that their bins sum to 1,
+// meaning that percentages and raw pixel counts can be compared
+// interchangeably.
+package distance
+
+import (
+	"errors"
+	"math"
+
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+var (
+	// ErrLengthMismatch is returned when the histograms to compare
+	// do not have the same amount of bins.
+	ErrLengthMismatch = errors.New("distance: histograms have a different amount of bins")
+
+	// ErrEmptyHistogram is returned when one of the histograms to
+	// compare has no bins or all of its bins are 0.
+	ErrEmptyHistogram = errors.New("distance: histogram is empty")
+
+	// ErrNegativeBin is returned when one of the histograms to
+	// compare has a negative bin.
+	ErrNegativeBin = errors.New("distance: histogram has a negative bin")
+
+	// ErrLayoutMismatch is returned when the histograms to compare
+	// were computed with different bin layouts.
+	ErrLayoutMismatch = errors.New("distance: histograms have different layouts")
+)
+
+// Metric compares two histograms, like Intersection or L1 do.
+type Metric func(a, b []float64) (float64, error)
+
+// Compare compares a and b with the given metric, making sure that
+// they were computed with the same bin layout. The raw pixel counts
+// are compared, so the result is not affected by rounding.
+func Compare(a, b histogram.Histogram, metric Metric) (float64, error) {
+
+	if a.Layout() != b.Layout() {
+		return 0, ErrLayoutMismatch
+	}
+
+	return metric(a.Counts(), b.Counts())
+
+}
+
+// Intersection returns the histogram intersection of a and b, as defined
+// by Swain and Ballard. The result is a similarity in [0,1], where 1
+// means that the histograms are identical.
+func Intersection(a, b []float64) (float64, error) {
+
+	p, q, err := normalizePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	var intersection float64
+	for i := range p {
+		intersection += math.Min(p[i], q[i])
+	}
+
+	return intersection, nil
+
+}
+
+// ChiSquared returns the symmetric chi-squared distance between a and b:
+// 1/2 * sum((a[i]-b[i])^2 / (a[i]+b[i])).
+// The result is in [0,1], where 0 means that the histograms are identical.
+func ChiSquared(a, b []float64) (float64, error) {
+
+	p, q, err := normalizePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	var distance float64
+	for i := range p {
+
+		// Both bins are empty, they don't contribute.
+		sum := p[i] + q[i]
+		if sum == 0 {
+			continue
+		}
+
+		difference := p[i] - q[i]
+		distance += difference * difference / sum
+
+	}
+
+	return distance / 2, nil
+
+}
+
+// Bhattacharyya returns the Bhattacharyya distance between a and b:
+// -ln(sum(sqrt(a[i]*b[i]))).
+// The result is in [0,+Inf], where 0 means that the histograms are identical
+// and +Inf means that they do not share any populated bin.
+func Bhattacharyya(a, b []float64) (float64, error) {
+
+	coefficient, err := bhattacharyyaCoefficient(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return -math.Log(coefficient), nil
+
+}
+
+// Hellinger returns the Hellinger distance between a and b:
+// sqrt(1 - sum(sqrt(a[i]*b[i]))).
+// The result is in [0,1], where 0 means that the histograms are identical.
+func Hellinger(a, b []float64) (float64, error) {
+
+	coefficient, err := bhattacharyyaCoefficient(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rounding errors may push the coefficient slightly above 1.
+	return math.Sqrt(math.Max(0, 1-coefficient)), nil
+
+}
+
+// L1 returns the Manhattan distance between a and b.
+// The result is in [0,2], where 0 means that the histograms are identical.
+func L1(a, b []float64) (float64, error) {
+
+	p, q, err := normalizePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	var distance float64
+	for i := range p {
+		distance += math.Abs(p[i] - q[i])
+	}
+
+	return distance, nil
+
+}
+
+// L2 returns the Euclidean distance between a and b.
+// The result is in [0,sqrt(2)], where 0 means that the histograms are identical.
+func L2(a, b []float64) (float64, error) {
+
+	p, q, err := normalizePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	var distance float64
+	for i := range p {
+		difference := p[i] - q[i]
+		distance += difference * difference
+	}
+
+	return math.Sqrt(distance), nil
+
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b.
+// The result is in [0,1], where 1 means that the histograms are
+// proportional to each other.
+func CosineSimilarity(a, b []float64) (float64, error) {
+
+	p, q, err := normalizePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	var dot, pNorm, qNorm float64
+	for i := range p {
+		dot += p[i] * q[i]
+		pNorm += p[i] * p[i]
+		qNorm += q[i] * q[i]
+	}
+
+	return dot / math.Sqrt(pNorm*qNorm), nil
+
+}
+
+// bhattacharyyaCoefficient returns sum(sqrt(a[i]*b[i])) for the normalized histograms.
+func bhattacharyyaCoefficient(a, b []float64) (float64, error) {
+
+	p, q, err := normalizePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	var coefficient float64
+	for i := range p {
+		coefficient += math.Sqrt(p[i] * q[i])
+	}
+
+	return coefficient, nil
+
+}
+
+// normalizePair checks that a and b can be compared and returns
+// copies of them whose bins sum to 1.
+func normalizePair(a, b []float64) ([]float64, []float64, error) {
+
+	if len(a) != len(b) {
+		return nil, nil, ErrLengthMismatch
+	}
+
+	p, err := normalize(a)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q, err := normalize(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return p, q, nil
+
+}
+
+// normalize returns a copy of bins whose values sum to 1.
+func normalize(bins []float64) ([]float64, error) {
+
+	var total float64
+	for _, bin := range bins {
+
+		if bin < 0 {
+			return nil, ErrNegativeBin
+		}
+
+		total += bin
+
+	}
+
+	if total == 0 {
+		return nil, ErrEmptyHistogram
+	}
+
+	normalized := make([]float64, len(bins))
+	for i, bin := range bins {
+		normalized[i] = bin / total
+	}
+
+	return normalized, nil
+
+}
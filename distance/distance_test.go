@@ -0,0 +1,116 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package distance
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+const tolerance = 1e-9
+
+func TestMetrics(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		metric func(a, b []float64) (float64, error)
+		a, b   []float64
+		want   float64
+	}{
+		{name: "Intersection identical", metric: Intersection, a: []float64{10, 20, 70}, b: []float64{1, 2, 7}, want: 1},
+		{name: "Intersection disjoint", metric: Intersection, a: []float64{50, 50, 0}, b: []float64{0, 0, 100}, want: 0},
+		{name: "Intersection partial", metric: Intersection, a: []float64{50, 50, 0}, b: []float64{0, 50, 50}, want: 0.5},
+		{name: "ChiSquared identical", metric: ChiSquared, a: []float64{10, 20, 70}, b: []float64{10, 20, 70}, want: 0},
+		{name: "ChiSquared disjoint", metric: ChiSquared, a: []float64{50, 50, 0}, b: []float64{0, 0, 100}, want: 1},
+		{name: "ChiSquared partial", metric: ChiSquared, a: []float64{50, 50, 0}, b: []float64{0, 50, 50}, want: 0.5},
+		{name: "Bhattacharyya identical", metric: Bhattacharyya, a: []float64{10, 20, 70}, b: []float64{10, 20, 70}, want: 0},
+		{name: "Bhattacharyya disjoint", metric: Bhattacharyya, a: []float64{50, 50, 0}, b: []float64{0, 0, 100}, want: math.Inf(1)},
+		{name: "Bhattacharyya partial", metric: Bhattacharyya, a: []float64{50, 50, 0}, b: []float64{0, 50, 50}, want: math.Log(2)},
+		{name: "Hellinger identical", metric: Hellinger, a: []float64{10, 20, 70}, b: []float64{10, 20, 70}, want: 0},
+		{name: "Hellinger disjoint", metric: Hellinger, a: []float64{50, 50, 0}, b: []float64{0, 0, 100}, want: 1},
+		{name: "Hellinger partial", metric: Hellinger, a: []float64{50, 50, 0}, b: []float64{0, 50, 50}, want: math.Sqrt(0.5)},
+		{name: "L1 disjoint", metric: L1, a: []float64{50, 50, 0}, b: []float64{0, 0, 100}, want: 2},
+		{name: "L1 partial", metric: L1, a: []float64{50, 50, 0}, b: []float64{0, 50, 50}, want: 1},
+		{name: "L2 disjoint", metric: L2, a: []float64{100, 0}, b: []float64{0, 100}, want: math.Sqrt2},
+		{name: "L2 partial", metric: L2, a: []float64{50, 50, 0}, b: []float64{0, 50, 50}, want: math.Sqrt(0.5)},
+		{name: "CosineSimilarity identical", metric: CosineSimilarity, a: []float64{1, 2, 3}, b: []float64{2, 4, 6}, want: 1},
+		{name: "CosineSimilarity disjoint", metric: CosineSimilarity, a: []float64{50, 50, 0}, b: []float64{0, 0, 100}, want: 0},
+		{name: "CosineSimilarity partial", metric: CosineSimilarity, a: []float64{50, 50, 0}, b: []float64{0, 50, 50}, want: 0.5},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := tt.metric(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("%s() unexpected error: %s", tt.name, err)
+			}
+
+			if got != tt.want && math.Abs(got-tt.want) > tolerance {
+				t.Errorf("%s() = %v, want %v", tt.name, got, tt.want)
+			}
+
+		})
+
+	}
+
+}
+
+func TestMetricsErrors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		a, b []float64
+		want error
+	}{
+		{name: "Length mismatch", a: []float64{1, 2}, b: []float64{1, 2, 3}, want: ErrLengthMismatch},
+		{name: "Empty histogram", a: []float64{0, 0}, b: []float64{1, 2}, want: ErrEmptyHistogram},
+		{name: "No bins", a: []float64{}, b: []float64{}, want: ErrEmptyHistogram},
+		{name: "Negative bin", a: []float64{1, 2}, b: []float64{-1, 2}, want: ErrNegativeBin},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			if _, err := L1(tt.a, tt.b); err != tt.want {
+				t.Errorf("L1() error = %v, want %v", err, tt.want)
+			}
+
+		})
+
+	}
+
+}
+
+func TestCompare(t *testing.T) {
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	with32Bins, err := histogram.New(img, histogram.Config32Bins, histogram.RoundClosest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	with64Bins, err := histogram.New(img, histogram.Config64Bins, histogram.RoundClosest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := Compare(with32Bins, with32Bins, L1); err != nil || got != 0 {
+		t.Errorf("Compare() = %v, %v, want 0, nil", got, err)
+	}
+
+	if _, err := Compare(with32Bins, with64Bins, L1); err != ErrLayoutMismatch {
+		t.Errorf("Compare() error = %v, want %v", err, ErrLayoutMismatch)
+	}
+
+}
@@ -0,0 +1,271 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package distance
+
+import (
+	"math"
+	"sort"
+
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+// epsilon is the amount of mass below which a
+// supply, demand or flow is considered to be 0.
+const epsilon = 1e-12
+
+// GroundDistance returns the cost of moving a unit of mass from bin i to bin j.
+// It must be non-negative.
+type GroundDistance func(i, j int) float64
+
+// HSVGround returns the ground distance between the bins of histograms
+// computed with cfg. It is the sum of the distances along each channel:
+// the Hue is treated as circular, with the period of cfg.HuePeriod, so the
+// first and the last Hue levels are next to each other, while Saturation
+// and Value are linear. With Version1, the last Hue level, which only holds
+// a Hue of 360°, is the same as the first one.
+// Each channel distance is scaled to [0,1], so that going from one end of
+// a channel to the other costs the same regardless of its amount of levels.
+// Gray bins have no Hue and a Saturation of 0, so moving mass between a gray
+// bin and another one only costs the difference in Saturation and Value.
+func HSVGround(cfg histogram.Config) GroundDistance {
+
+	period := cfg.HuePeriod()
+	hueScale := math.Max(float64(period/2), 1)
+	saturationScale := math.Max(float64(cfg.SaturationLevels-1), 1)
+	valueScale := math.Max(float64(cfg.ValueLevels-1), 1)
+	grayScale := math.Max(float64(cfg.GrayLevels-1), 1)
+
+	return func(i, j int) float64 {
+
+		iHue, iSaturation, iValue := cfg.Levels(i)
+		jHue, jSaturation, jValue := cfg.Levels(j)
+
+		var hueDistance float64
+		if !cfg.Gray(i) && !cfg.Gray(j) {
+			hueDistance = math.Abs(float64(iHue%period - jHue%period))
+			hueDistance = math.Min(hueDistance, float64(period)-hueDistance)
+		}
+
+		// Value levels are compared on [0,1],
+		// since gray bins have their own amount.
+		iValueScale, jValueScale := valueScale, valueScale
+		if cfg.Gray(i) {
+			iValueScale = grayScale
+		}
+		if cfg.Gray(j) {
+			jValueScale = grayScale
+		}
+
+		return hueDistance/hueScale +
+			math.Abs(float64(iSaturation-jSaturation))/saturationScale +
+			math.Abs(float64(iValue)/iValueScale-float64(jValue)/jValueScale)
+
+	}
+
+}
+
+// EMD returns the Earth Mover's Distance between a and b, that is the minimum
+// cost of turning a into b, where moving a unit of mass from bin i to bin j
+// costs ground(i, j). Since the histograms are normalized, the result is
+// expressed in units of ground distance.
+func EMD(a, b []float64, ground GroundDistance) (float64, error) {
+
+	p, q, err := normalizePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	n := len(p)
+	costs := make([][]float64, n)
+	for i := range costs {
+
+		costs[i] = make([]float64, n)
+		for j := range costs[i] {
+			costs[i][j] = ground(i, j)
+		}
+
+	}
+
+	flows := solveTransportation(p, q, costs)
+
+	var distance float64
+	for i := range flows {
+		for j := range flows[i] {
+			distance += flows[i][j] * costs[i][j]
+		}
+	}
+
+	return distance, nil
+
+}
+
+// CircularEMD returns the Earth Mover's Distance between two circular
+// one-dimensional histograms, such as Hue-only histograms, where moving
+// a unit of mass between two adjacent bins costs 1 and the first and the
+// last bins are adjacent.
+func CircularEMD(a, b []float64) (float64, error) {
+
+	p, q, err := normalizePair(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	// The distance is the sum of the absolute differences between the
+	// cumulative distributions, once shifted by their median difference.
+	differences := make([]float64, len(p))
+	var cumulative float64
+	for i := range p {
+		cumulative += p[i] - q[i]
+		differences[i] = cumulative
+	}
+
+	sorted := append([]float64(nil), differences...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var distance float64
+	for _, difference := range differences {
+		distance += math.Abs(difference - median)
+	}
+
+	return distance, nil
+
+}
+
+// solveTransportation returns the flows moving supply into demand with the
+// minimum total cost, using successive shortest paths on the residual graph.
+// supply and demand must have the same total.
+func solveTransportation(supply, demand []float64, costs [][]float64) [][]float64 {
+
+	n := len(supply)
+	supply = append([]float64(nil), supply...)
+	demand = append([]float64(nil), demand...)
+
+	flows := make([][]float64, n)
+	for i := range flows {
+		flows[i] = make([]float64, n)
+	}
+
+	// Nodes [0,n) are the supplies, [n,2n) the demands,
+	// followed by the sink and the source.
+	sink, source := 2*n, 2*n+1
+	nodes := 2*n + 2
+	potentials := make([]float64, nodes)
+	distances := make([]float64, nodes)
+	previous := make([]int, nodes)
+	visited := make([]bool, nodes)
+
+	// Every augmentation empties a supply, a demand or a flow,
+	// bound the iterations to protect against rounding errors.
+	for iteration := 0; iteration < 2*n*n+2*n; iteration++ {
+
+		for i := range distances {
+			distances[i] = math.Inf(1)
+			previous[i] = -1
+			visited[i] = false
+		}
+		distances[source] = 0
+
+		// Dijkstra on the dense residual graph, using reduced costs.
+		for {
+
+			u := -1
+			for v := 0; v < nodes; v++ {
+				if !visited[v] && !math.IsInf(distances[v], 1) && (u == -1 || distances[v] < distances[u]) {
+					u = v
+				}
+			}
+
+			if u == -1 {
+				break
+			}
+			visited[u] = true
+
+			// Rounding errors may produce slightly negative reduced costs,
+			// nodes whose distance is final must not be updated.
+			relax := func(v int, cost float64) {
+				if visited[v] {
+					return
+				}
+				candidate := distances[u] + cost + potentials[u] - potentials[v]
+				if candidate < distances[v] {
+					distances[v] = candidate
+					previous[v] = u
+				}
+			}
+
+			switch {
+			case u == source:
+				for i := 0; i < n; i++ {
+					if supply[i] > epsilon {
+						relax(i, 0)
+					}
+				}
+			case u < n:
+				for j := 0; j < n; j++ {
+					relax(n+j, costs[u][j])
+				}
+			case u < 2*n:
+				j := u - n
+				for i := 0; i < n; i++ {
+					if flows[i][j] > epsilon {
+						relax(i, -costs[i][j])
+					}
+				}
+				if demand[j] > epsilon {
+					relax(sink, 0)
+				}
+			}
+
+		}
+
+		// All the mass has been moved.
+		if math.IsInf(distances[sink], 1) {
+			break
+		}
+
+		for v := range potentials {
+			if !math.IsInf(distances[v], 1) {
+				potentials[v] += distances[v]
+			}
+		}
+
+		// Find how much mass can be moved along the path.
+		amount := math.Inf(1)
+		for v := sink; v != source; v = previous[v] {
+
+			u := previous[v]
+			switch {
+			case u == source:
+				amount = math.Min(amount, supply[v])
+			case v == sink:
+				amount = math.Min(amount, demand[u-n])
+			case u >= n:
+				amount = math.Min(amount, flows[v][u-n])
+			}
+
+		}
+
+		for v := sink; v != source; v = previous[v] {
+
+			u := previous[v]
+			switch {
+			case u == source:
+				supply[v] -= amount
+			case v == sink:
+				demand[u-n] -= amount
+			case u < n:
+				flows[u][v-n] += amount
+			default:
+				flows[v][u-n] -= amount
+			}
+
+		}
+
+	}
+
+	return flows
+
+}
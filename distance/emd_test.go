@@ -0,0 +1,237 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package distance
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+func TestEMD(t *testing.T) {
+
+	linear := func(i, j int) float64 {
+		return math.Abs(float64(i - j))
+	}
+
+	circular8 := func(i, j int) float64 {
+		d := math.Abs(float64(i - j))
+		return math.Min(d, 8-d)
+	}
+
+	tests := []struct {
+		name   string
+		a, b   []float64
+		ground GroundDistance
+		want   float64
+	}{
+		{
+			name:   "Identical",
+			a:      []float64{10, 20, 30, 40},
+			b:      []float64{10, 20, 30, 40},
+			ground: linear,
+			want:   0,
+		},
+		{
+			name:   "Linear shift",
+			a:      []float64{100, 0, 0, 0},
+			b:      []float64{0, 0, 0, 100},
+			ground: linear,
+			want:   3,
+		},
+		{
+			name:   "Linear spread",
+			a:      []float64{50, 0, 50, 0},
+			b:      []float64{0, 50, 0, 50},
+			ground: linear,
+			want:   1,
+		},
+		{
+			name:   "Circular wrap",
+			a:      []float64{100, 0, 0, 0, 0, 0, 0, 0},
+			b:      []float64{0, 0, 0, 0, 0, 0, 0, 100},
+			ground: circular8,
+			want:   1,
+		},
+		{
+			name:   "Circular mixed",
+			a:      []float64{30, 10, 0, 0, 20, 0, 0, 40},
+			b:      []float64{0, 25, 25, 0, 0, 10, 40, 0},
+			ground: circular8,
+			want:   mustCircularEMD(t, []float64{30, 10, 0, 0, 20, 0, 0, 40}, []float64{0, 25, 25, 0, 0, 10, 40, 0}),
+		},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := EMD(tt.a, tt.b, tt.ground)
+			if err != nil {
+				t.Fatalf("EMD() unexpected error: %s", err)
+			}
+
+			if math.Abs(got-tt.want) > tolerance {
+				t.Errorf("EMD() = %v, want %v", got, tt.want)
+			}
+
+		})
+
+	}
+
+}
+
+func TestCircularEMD(t *testing.T) {
+
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{name: "Identical", a: []float64{1, 2, 3, 4}, b: []float64{1, 2, 3, 4}, want: 0},
+		{name: "Adjacent", a: []float64{1, 0, 0, 0}, b: []float64{0, 1, 0, 0}, want: 1},
+		{name: "Wrap", a: []float64{1, 0, 0, 0}, b: []float64{0, 0, 0, 1}, want: 1},
+		{name: "Opposite", a: []float64{1, 0, 0, 0}, b: []float64{0, 0, 1, 0}, want: 2},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			if got := mustCircularEMD(t, tt.a, tt.b); math.Abs(got-tt.want) > tolerance {
+				t.Errorf("CircularEMD() = %v, want %v", got, tt.want)
+			}
+
+		})
+
+	}
+
+}
+
+func TestHSVGround(t *testing.T) {
+
+	ground := HSVGround(histogram.Config64Bins)
+
+	tests := []struct {
+		name string
+		i, j int
+		want float64
+	}{
+		{name: "Same bin", i: 5, j: 5, want: 0},
+		{name: "Adjacent hue", i: 0, j: 4, want: 1.0 / 3},
+		{name: "First and second to last hue", i: 0, j: 24, want: 1.0 / 3},
+		{name: "Hue 360", i: 0, j: 28, want: 0},
+		{name: "Hue 360 and second to last hue", i: 28, j: 24, want: 1.0 / 3},
+		{name: "Opposite hue", i: 0, j: 12, want: 1},
+		{name: "Saturation range", i: 0, j: 3, want: 1},
+		{name: "Value range", i: 0, j: 32, want: 1},
+		{name: "All channels", i: 1, j: 4 + 3 + 32, want: 1.0/3 + 2.0/3 + 1},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			if got := ground(tt.i, tt.j); math.Abs(got-tt.want) > tolerance {
+				t.Errorf("HSVGround()(%d, %d) = %v, want %v", tt.i, tt.j, got, tt.want)
+			}
+
+			if got := ground(tt.j, tt.i); math.Abs(got-tt.want) > tolerance {
+				t.Errorf("HSVGround()(%d, %d) = %v, want %v", tt.j, tt.i, got, tt.want)
+			}
+
+		})
+
+	}
+
+}
+
+func TestHSVGroundVersion2(t *testing.T) {
+
+	ground := HSVGround(histogram.Config64BinsV2)
+
+	tests := []struct {
+		name string
+		i, j int
+		want float64
+	}{
+		{name: "Adjacent hue", i: 0, j: 4, want: 0.25},
+		{name: "First and last hue", i: 0, j: 28, want: 0.25},
+		{name: "Opposite hue", i: 0, j: 16, want: 1},
+	}
+
+	for _, tt := range tests {
+
+		if got := ground(tt.i, tt.j); math.Abs(got-tt.want) > tolerance {
+			t.Errorf("%s: HSVGround()(%d, %d) = %v, want %v", tt.name, tt.i, tt.j, got, tt.want)
+		}
+
+		if got := ground(tt.j, tt.i); math.Abs(got-tt.want) > tolerance {
+			t.Errorf("%s: HSVGround()(%d, %d) = %v, want %v", tt.name, tt.j, tt.i, got, tt.want)
+		}
+
+	}
+
+}
+
+func TestHSVGroundWithGrayBins(t *testing.T) {
+
+	cfg := histogram.Config64BinsGray
+	ground := HSVGround(cfg)
+	black, white := cfg.Index(cfg.HueLevels, 0, 0), cfg.Index(cfg.HueLevels, 0, cfg.GrayLevels-1)
+
+	tests := []struct {
+		name string
+		i, j int
+		want float64
+	}{
+		{name: "Same gray", i: black, j: black, want: 0},
+		{name: "Black and white", i: black, j: white, want: 1},
+		{name: "Adjacent grays", i: black, j: black + 1, want: 1.0 / 3},
+		{name: "Dark desaturated color", i: black, j: cfg.Index(4, 0, 0), want: 0},
+		{name: "Saturated light color", i: white, j: cfg.Index(2, 3, 1), want: 1},
+	}
+
+	for _, tt := range tests {
+
+		if got := ground(tt.i, tt.j); math.Abs(got-tt.want) > tolerance {
+			t.Errorf("%s: HSVGround()(%d, %d) = %v, want %v", tt.name, tt.i, tt.j, got, tt.want)
+		}
+
+		if got := ground(tt.j, tt.i); math.Abs(got-tt.want) > tolerance {
+			t.Errorf("%s: HSVGround()(%d, %d) = %v, want %v", tt.name, tt.j, tt.i, got, tt.want)
+		}
+
+	}
+
+}
+
+func BenchmarkEMDWith64Bins(b *testing.B) {
+
+	p := make([]float64, 64)
+	q := make([]float64, 64)
+	for i := range p {
+		p[i] = float64(i % 7)
+		q[i] = float64(i % 5)
+	}
+
+	ground := HSVGround(histogram.Config64Bins)
+	for i := 0; i < b.N; i++ {
+		_, _ = EMD(p, q, ground)
+	}
+
+}
+
+func mustCircularEMD(t *testing.T, a, b []float64) float64 {
+
+	distance, err := CircularEMD(a, b)
+	if err != nil {
+		t.Fatalf("CircularEMD() unexpected error: %s", err)
+	}
+
+	return distance
+
+}
@@ -5,6 +5,7 @@
 package histogram
 
 import (
+	"context"
 	"image"
 	"image/jpeg"
 	"log"
@@ -60,7 +61,7 @@ func TestWith32Bins(t *testing.T) {
 	tests := []struct {
 		name      string
 		img       image.Image
-		roundType int
+		roundType RoundingMode
 		want      []float64
 	}{
 		{
@@ -127,7 +128,7 @@ func TestWith32BinsConcurrent(t *testing.T) {
 	tests := []struct {
 		name      string
 		img       image.Image
-		roundType int
+		roundType RoundingMode
 		want      []float64
 	}{
 		{
@@ -196,6 +197,36 @@ func TestWith32BinsConcurrent(t *testing.T) {
  ***********************************************************************************************************************
  */
 
+func TestWith32BinsContext(t *testing.T) {
+
+	//Photo by Mohsin khan from Pexels
+	img := getImageByRelativePath(`../pictures/tree_medium.jpg`)
+
+	got, err := With32BinsContext(context.Background(), img, RoundClosest)
+	if want := With32BinsConcurrent(img, RoundClosest); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("With32BinsContext() = %v, %v, want %v", got, err, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, err := With32BinsContext(ctx, img, RoundClosest); got != nil || err != context.Canceled {
+		t.Errorf("With32BinsContext() with a canceled context = %v, %v, want %v", got, err, context.Canceled)
+	}
+
+}
+
+func TestWith32BinsInvalidRounder(t *testing.T) {
+
+	if got := With32Bins(image.NewRGBA(image.Rect(0, 0, 2, 2)), RoundingMode(7)); got != nil {
+		t.Errorf("With32Bins() with an invalid rounder = %v, want nil", got)
+	}
+
+	if _, err := With32BinsContext(context.Background(), image.NewRGBA(image.Rect(0, 0, 2, 2)), RoundingMode(7)); err != ErrInvalidRoundingMode {
+		t.Errorf("With32BinsContext() with an invalid rounder error = %v, want %v", err, ErrInvalidRoundingMode)
+	}
+
+}
+
 func BenchmarkWith32Bins(b *testing.B) {
 
 	img := getImageByRelativePath(`../pictures/beach_medium.jpg`)
@@ -5,11 +5,8 @@
 package histogram
 
 import (
+	"context"
 	"image"
-	"math"
-	"runtime"
-
-	"github.com/AlessandroPomponio/hsv/conversion"
 )
 
 // With64Bins returns a color histogram with 64 bins for the input image.
@@ -22,44 +19,8 @@ import (
 // The Hue will be mapped to 8 levels, indexes {0,4,8,12,16,20,24,28}.
 // The Saturation will be mapped to 4 levels, indexes H_level + {0,1,2,3}.
 // The Value will be mapped to 2 levels, indexes H_level + S_level + {0,32}.
-func With64Bins(img image.Image, roundType int) []float64 {
-
-	bins := make([]float64, 64)
-	xBound := img.Bounds().Dx()
-	yBound := img.Bounds().Dy()
-
-	for x := 0; x < xBound; x++ {
-
-		for y := 0; y < yBound; y++ {
-
-			h, s, v := conversion.RGBAToHSV(img.At(x, y).RGBA())
-
-			// hueBin in [0,7].
-			// Try to map hue in equally-sized
-			// levels by dividing it for 360/7.
-			hueBin := int(h / 51.42857142857143)
-
-			// saturationBin in [0,3]
-			// Try to map saturation in equally-sized
-			// levels by dividing it for 100/3.
-			saturationBin := int(s / 33.33333333333333)
-
-			// valueBin in [0,1]
-			// Try to map value in equally-sized
-			// levels by dividing it for a value
-			// that's just above 50.
-			valueBin := int(v / 50.0000000001)
-			//valueBin = 0
-
-			index := 4*hueBin + saturationBin + 32*valueBin
-			bins[index]++
-
-		}
-
-	}
-
-	return normalize64BinsHistogram(roundType, xBound, yBound, bins)
-
+func With64Bins(img image.Image, rounder Rounder) []float64 {
+	return WithConfig(img, Config64Bins, rounder)
 }
 
 // With64BinsConcurrent returns a color histogram with 64 bins for the input image.
@@ -73,96 +34,17 @@ func With64Bins(img image.Image, roundType int) []float64 {
 // The Hue will be mapped to 8 levels, indexes {0,4,8,12,16,20,24,28}.
 // The Saturation will be mapped to 4 levels, indexes H_level + {0,1,2,3}.
 // The Value will be mapped to 2 levels, indexes H_level + S_level + {0,32}.
-func With64BinsConcurrent(img image.Image, roundType int) []float64 {
-
-	bins := make([]float64, 64)
-	cpuAmt := runtime.NumCPU()
-	binChannel := make(chan []float64, cpuAmt/2)
-
-	// Split image into NumCPU sub-images to help speed up the computation.
-	rectangles := splitInto(cpuAmt, img.Bounds())
-	for _, rectangle := range rectangles {
-		go calculate64BinsForRectangle(rectangle, img, binChannel)
-	}
-
-	// Gather the results from all goroutines and sum them.
-	for i := 0; i < len(rectangles); i++ {
-
-		currentBins := <-binChannel
-
-		for i := 0; i < 64; i++ {
-			bins[i] += currentBins[i]
-		}
-
-	}
-
-	return normalize64BinsHistogram(roundType, img.Bounds().Dx(), img.Bounds().Dy(), bins)
-
-}
-
-func calculate64BinsForRectangle(rectangle image.Rectangle, img image.Image, outputChan chan []float64) {
-
-	bins := make([]float64, 64)
-	for x := rectangle.Min.X; x <= rectangle.Max.X; x++ {
-
-		for y := rectangle.Min.Y; y <= rectangle.Max.Y; y++ {
-
-			h, s, v := conversion.RGBAToHSV(img.At(x, y).RGBA())
-
-			// hueBin in [0,7].
-			// Try to map hue in equally-sized
-			// levels by dividing it for 360/7.
-			hueBin := int(h / 51.42857142857143)
-
-			// saturationBin in [0,3]
-			// Try to map saturation in equally-sized
-			// levels by dividing it for 100/3.
-			saturationBin := int(s / 33.333333333)
-
-			// valueBin in [0,1]
-			// Try to map value in equally-sized
-			// levels by dividing it for a value
-			// that's just above 50.
-			valueBin := int(v / 50.0000000000001)
-
-			index := 4*hueBin + saturationBin + 32*valueBin
-			bins[index]++
-
-		}
-	}
-
-	outputChan <- bins
+func With64BinsConcurrent(img image.Image, rounder Rounder) []float64 {
+	return WithConfigConcurrent(img, Config64Bins, rounder)
 }
 
-// normalize64BinsHistogram normalizes 64-bin histograms by the amount of pixels in the image.
-// It also makes sure that the sum of the percentages in bins[i] and bins[i+32] is equal to
-// the rounded value of the percentage of their sum.
-// bins[i] + bins[i+32] = round((bins[i] + bins[i+32]) * 100 / pixels)
-func normalize64BinsHistogram(roundType int, width, height int, bins []float64) []float64 {
-
-	pixels := float64(width * height)
-	var roundFunction func(x float64) float64
-
-	switch roundType {
-	case RoundClosest:
-		roundFunction = math.Round
-	case RoundUp:
-		roundFunction = math.Ceil
-	case RoundDown:
-		roundFunction = math.Trunc
-	default:
-		return nil
-	}
-
-	for i := 0; i < 32; i++ {
-
-		totalPercentage := roundFunction((bins[i] + bins[i+32]) * 100 / pixels)
-		firstHalfPercentage := roundFunction(bins[i] * 100 / pixels)
-		bins[i] = firstHalfPercentage
-		bins[i+32] = totalPercentage - firstHalfPercentage
-
-	}
+// With64BinsContext returns a color histogram with 64 bins for the input image,
+// like With64BinsConcurrent does. The goroutines stop as soon as ctx is
+// done, in which case nil and ctx.Err() are returned.
+// An error is also returned if the rounder is not valid or the image is empty.
+func With64BinsContext(ctx context.Context, img image.Image, rounder Rounder) ([]float64, error) {
 
-	return bins
+	histogram, err := NewContext(ctx, img, Config64Bins, rounder, Options{})
+	return histogram.percentages, err
 
 }
@@ -5,6 +5,7 @@
 package histogram
 
 import (
+	"context"
 	"image"
 	"reflect"
 	"testing"
@@ -22,7 +23,7 @@ func TestWith64Bins(t *testing.T) {
 	tests := []struct {
 		name      string
 		img       image.Image
-		roundType int
+		roundType RoundingMode
 		want      []float64
 	}{
 		{
@@ -89,7 +90,7 @@ func TestWith64BinsConcurrent(t *testing.T) {
 	tests := []struct {
 		name      string
 		img       image.Image
-		roundType int
+		roundType RoundingMode
 		want      []float64
 	}{
 		{
@@ -158,6 +159,24 @@ func TestWith64BinsConcurrent(t *testing.T) {
  ***********************************************************************************************************************
  */
 
+func TestWith64BinsContext(t *testing.T) {
+
+	//Photo by Mohsin khan from Pexels
+	img := getImageByRelativePath(`../pictures/tree_medium.jpg`)
+
+	got, err := With64BinsContext(context.Background(), img, RoundClosest)
+	if want := With64BinsConcurrent(img, RoundClosest); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("With64BinsContext() = %v, %v, want %v", got, err, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, err := With64BinsContext(ctx, img, RoundClosest); got != nil || err != context.Canceled {
+		t.Errorf("With64BinsContext() with a canceled context = %v, %v, want %v", got, err, context.Canceled)
+	}
+
+}
+
 func BenchmarkWith64Bins(b *testing.B) {
 
 	img := getImageByRelativePath(`../pictures/beach_medium.jpg`)
@@ -0,0 +1,48 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+// AlphaPolicy decides how much a pixel counts towards a histogram, given its
+// alpha value in [0, 0xffff], as returned by color.Color's RGBA method.
+// Pixels with a weight of 0 are skipped and, like the others, are not only
+// excluded from the bins, but also from the total amount of pixels the
+// percentages are computed from. If no pixel is counted, ErrEmptyImage is returned.
+// Without an AlphaPolicy, fully transparent pixels are counted as black,
+// since that's the color RGBAToHSV maps them to.
+type AlphaPolicy func(a uint32) float64
+
+// SkipTransparent is an AlphaPolicy that counts every pixel once,
+// except for the fully transparent ones, which are skipped.
+func SkipTransparent(a uint32) float64 {
+
+	if a == 0 {
+		return 0
+	}
+
+	return 1
+
+}
+
+// WeightByAlpha is an AlphaPolicy that weights every pixel by its opacity,
+// so that a pixel with half the maximum alpha counts as half a pixel.
+func WeightByAlpha(a uint32) float64 {
+	return float64(a) / 0xffff
+}
+
+// AlphaThreshold returns an AlphaPolicy that counts once every pixel whose
+// alpha is at least minAlpha, in [0, 0xffff], and skips the others.
+func AlphaThreshold(minAlpha uint32) AlphaPolicy {
+
+	return func(a uint32) float64 {
+
+		if a < minAlpha {
+			return 0
+		}
+
+		return 1
+
+	}
+
+}
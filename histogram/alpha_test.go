@@ -0,0 +1,208 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// productImage returns a 10x10 image with a transparent background, a 4x4 opaque
+// blue square in the middle and a row of 10 half transparent red pixels.
+func productImage() *image.NRGBA {
+
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for x := 3; x < 7; x++ {
+		for y := 3; y < 7; y++ {
+			img.Set(x, y, color.NRGBA{B: 255, A: 255})
+		}
+	}
+
+	for x := 0; x < 10; x++ {
+		img.Set(x, 9, color.NRGBA{R: 255, A: 128})
+	}
+
+	return img
+
+}
+
+func TestAlphaPolicies(t *testing.T) {
+
+	img := productImage()
+	black := Config32Bins.quantize(0, 0, 0)
+	red := Config32Bins.quantize(0, 100, 100)
+	blue := Config32Bins.quantize(240, 100, 100)
+	halfAlpha := 128.0 / 255
+
+	tests := []struct {
+		name   string
+		alpha  AlphaPolicy
+		pixels float64
+		black  float64
+		red    float64
+		blue   float64
+	}{
+		{"Count every pixel", nil, 100, 74, 10, 16},
+		{"SkipTransparent", SkipTransparent, 26, 0, 10, 16},
+		{"WeightByAlpha", WeightByAlpha, 16 + 10*halfAlpha, 0, 10 * halfAlpha, 16},
+		{"AlphaThreshold", AlphaThreshold(0x9000), 16, 0, 0, 16},
+		{"AlphaThreshold of 0", AlphaThreshold(0), 100, 74, 10, 16},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			for _, opts := range []Options{{Alpha: tt.alpha}, {Alpha: tt.alpha, Workers: 3, Tiling: Tiling{Size: image.Pt(3, 3)}}} {
+
+				got, err := NewWithOptions(img, Config32Bins, RoundNone, opts)
+				if err != nil {
+					t.Fatalf("NewWithOptions() error = %v", err)
+				}
+
+				counts := got.Counts()
+				if !closeTo(got.Pixels(), tt.pixels) || !closeTo(counts[black], tt.black) || !closeTo(counts[red], tt.red) || !closeTo(counts[blue], tt.blue) {
+					t.Errorf("NewWithOptions() pixels = %v, counts = %v, want %v pixels, %v black, %v red and %v blue",
+						got.Pixels(), counts, tt.pixels, tt.black, tt.red, tt.blue)
+				}
+
+				if percentage := got.Percentages()[blue]; !closeTo(percentage, tt.blue*100/tt.pixels) {
+					t.Errorf("NewWithOptions() blue percentage = %v, want %v", percentage, tt.blue*100/tt.pixels)
+				}
+
+			}
+
+		})
+
+	}
+
+}
+
+// closeTo reports whether a and b only differ by rounding errors.
+func closeTo(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
+
+func TestAlphaPoliciesWithEngines(t *testing.T) {
+
+	img := productImage()
+	ycbcr := lobsterCrop()
+	lut := newTestLUT(t, Config64Bins)
+
+	for _, alpha := range []AlphaPolicy{SkipTransparent, WeightByAlpha, AlphaThreshold(0x8000)} {
+
+		opts := Options{Workers: 2, Alpha: alpha}
+		engines := map[string]*Engine{
+			"Engine":     newTestEngine(t, Config64Bins, opts),
+			"LUT Engine": lut.NewEngine(opts),
+		}
+
+		for name, engine := range engines {
+
+			for _, img := range []image.Image{img, ycbcr} {
+
+				want, _ := NewWithOptions(img, Config64Bins, RoundClosest, opts)
+				if got, err := engine.New(img, RoundClosest); err != nil || !reflect.DeepEqual(got, want) {
+					t.Errorf("%s.New() = %v, %v, want %v", name, got.Counts(), err, want.Counts())
+				}
+
+			}
+
+			engine.Close()
+
+		}
+
+	}
+
+}
+
+func TestAlphaPoliciesWithTransparentImage(t *testing.T) {
+
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+
+	for _, alpha := range []AlphaPolicy{SkipTransparent, WeightByAlpha, AlphaThreshold(1)} {
+
+		if _, err := NewContext(context.Background(), img, Config32Bins, RoundClosest, Options{Alpha: alpha}); err != ErrEmptyImage {
+			t.Errorf("NewContext() of a transparent image error = %v, want %v", err, ErrEmptyImage)
+		}
+
+		engine := newTestEngine(t, Config32Bins, Options{Alpha: alpha})
+		if _, err := engine.New(img, RoundClosest); err != ErrEmptyImage {
+			t.Errorf("Engine.New() of a transparent image error = %v, want %v", err, ErrEmptyImage)
+		}
+		engine.Close()
+
+	}
+
+	// Y'CbCr images are opaque, so a LUT skips all of their pixels at once.
+	engine := newTestLUT(t, Config32Bins).NewEngine(Options{Alpha: AlphaThreshold(0xffff + 1)})
+	defer engine.Close()
+	if _, err := engine.New(lobsterCrop(), RoundClosest); err != ErrEmptyImage {
+		t.Errorf("LUT Engine.New() skipping every pixel error = %v, want %v", err, ErrEmptyImage)
+	}
+
+}
+
+func TestAlphaPoliciesSequential(t *testing.T) {
+
+	img := productImage()
+	lut := newTestLUT(t, Config64Bins)
+	rect := image.Rect(2, 2, 10, 10)
+
+	// The sequential builders sum the weights in a different order than the
+	// concurrent ones, so the counts are compared with a tolerance.
+	for _, alpha := range []AlphaPolicy{SkipTransparent, WeightByAlpha, AlphaThreshold(0x8000)} {
+
+		want, _ := NewContext(context.Background(), img, Config64Bins, RoundClosest, Options{Alpha: alpha})
+		if got, err := NewWithAlpha(img, Config64Bins, RoundClosest, alpha); err != nil || !sameCounts(got, want) {
+			t.Errorf("NewWithAlpha() = %v, %v, want %v", got.Counts(), err, want.Counts())
+		}
+		if got, err := lut.NewWithAlpha(img, RoundClosest, alpha); err != nil || !sameCounts(got, want) {
+			t.Errorf("LUT.NewWithAlpha() = %v, %v, want %v", got.Counts(), err, want.Counts())
+		}
+
+		want, _ = ForRegionContext(context.Background(), img, rect, Config64Bins, RoundClosest, Options{Alpha: alpha})
+		if got, err := ForRegionWithAlpha(img, rect, Config64Bins, RoundClosest, alpha); err != nil || !sameCounts(got, want) {
+			t.Errorf("ForRegionWithAlpha() = %v, %v, want %v", got.Counts(), err, want.Counts())
+		}
+		if got, err := lut.ForRegionWithAlpha(img, rect, RoundClosest, alpha); err != nil || !sameCounts(got, want) {
+			t.Errorf("LUT.ForRegionWithAlpha() = %v, %v, want %v", got.Counts(), err, want.Counts())
+		}
+
+	}
+
+	// Without a policy, the transparent background is counted as black.
+	skipped, _ := NewWithAlpha(img, Config64Bins, RoundNone, SkipTransparent)
+	if all, _ := New(img, Config64Bins, RoundNone); skipped.Pixels() != 26 || all.Pixels() != 100 {
+		t.Errorf("NewWithAlpha() pixels = %v, New() pixels = %v, want 26 and 100", skipped.Pixels(), all.Pixels())
+	}
+
+	if _, err := NewWithAlpha(image.NewNRGBA(image.Rect(0, 0, 8, 8)), Config64Bins, RoundClosest, SkipTransparent); err != ErrEmptyImage {
+		t.Errorf("NewWithAlpha() of a transparent image error = %v, want %v", err, ErrEmptyImage)
+	}
+
+}
+
+// sameCounts reports whether a and b have the same amount of pixels and
+// counts, apart from rounding errors.
+func sameCounts(a, b Histogram) bool {
+
+	aCounts, bCounts := a.Counts(), b.Counts()
+	if !closeTo(a.Pixels(), b.Pixels()) || len(aCounts) != len(bCounts) {
+		return false
+	}
+
+	for i := range aCounts {
+		if !closeTo(aCounts[i], bCounts[i]) {
+			return false
+		}
+	}
+
+	return true
+
+}
@@ -0,0 +1,341 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"math"
+
+	"github.com/AlessandroPomponio/hsv/conversion"
+)
+
+// Config describes how the HSV color space is quantized into bins.
+// Each channel is mapped to the given amount of levels and the bin
+// index is computed as
+// saturationLevel + SaturationLevels*(hueLevel + HueLevels*valueLevel),
+// so that, with 8 Hue and 4 Saturation levels, the index becomes the
+// familiar 4*hueLevel + saturationLevel + 32*valueLevel.
+// If the Config has gray levels, their bins follow all the others.
+type Config struct {
+
+	// HueLevels is the amount of levels the Hue is mapped to.
+	// How the Hue is divided among them depends on the Version.
+	HueLevels int
+
+	// SaturationLevels is the amount of levels the Saturation is mapped to.
+	// Unless EqualSaturation is set, the Saturation is divided in
+	// SaturationLevels-1 equally-sized steps of 100/(SaturationLevels-1),
+	// so the last level only holds a Saturation of exactly 100.
+	SaturationLevels int
+
+	// EqualSaturation divides the Saturation in SaturationLevels
+	// equally-sized intervals of 100/SaturationLevels instead,
+	// the last of which also holds a Saturation of exactly 100.
+	EqualSaturation bool
+
+	// ValueLevels is the amount of levels the Value is mapped to.
+	// The Value is divided in ValueLevels equally-sized intervals,
+	// each one including its upper bound.
+	ValueLevels int
+
+	// Exact makes the histogram use the unrounded HSV values of each pixel,
+	// avoiding the artifacts caused by rounding them to whole degrees and
+	// percentage points before mapping them to their levels.
+	Exact bool
+
+	// Version selects how the Hue is quantized. The zero value is Version1,
+	// the quantization of the original 32 and 64 bin histograms.
+	Version Version
+
+	// RedCentered rotates the Hue levels of Version2 by half a level,
+	// so that the first one is centered on red, at 0°, instead of
+	// starting from it. It's not valid with Version1.
+	RedCentered bool
+
+	// GrayLevels is the amount of achromatic bins, for blacks, grays and
+	// whites, which would otherwise share the bins of the low saturation
+	// reds, since their Hue is 0. The colors whose Saturation is below
+	// GraySaturation, or whose Value is below GrayValue, are mapped to the
+	// gray bins by their Value, which is divided like it is for ValueLevels.
+	// The gray bins have a Hue level of HueLevels, a Saturation level of 0
+	// and their gray level as Value level, so that, for example,
+	// Index(HueLevels, 0, 0) is the bin of the darkest grays.
+	GrayLevels int
+
+	// GraySaturation is the Saturation, in [0,100], below which colors are
+	// gray. It can only be set if GrayLevels is positive.
+	GraySaturation float64
+
+	// GrayValue is the Value, in [0,100], below which colors are black,
+	// that is the darkest gray. It can only be set if GrayLevels is positive.
+	GrayValue float64
+}
+
+// Version identifies how a Config quantizes the Hue. Histograms are only
+// comparable if they were computed with the same Version, so the original
+// quantization stays available for histograms that have been stored.
+type Version int
+
+const (
+	// Version1 divides the Hue in HueLevels-1 equally-sized steps of
+	// 360/(HueLevels-1), like the original 32 and 64 bin histograms.
+	// The last level only holds a Hue of exactly 360°, so the other levels
+	// are larger than they should be and red is split between the first
+	// level and the last one.
+	Version1 Version = iota
+
+	// Version2 divides the Hue in HueLevels equally-sized steps of
+	// 360/HueLevels, wrapping around so that 360° is mapped like 0°.
+	Version2
+)
+
+var (
+	// Config32Bins is the layout used by With32Bins:
+	// 8 Hue levels, 4 Saturation levels and no Value levels.
+	Config32Bins = Config{HueLevels: 8, SaturationLevels: 4, ValueLevels: 1}
+
+	// Config64Bins is the layout used by With64Bins:
+	// 8 Hue levels, 4 Saturation levels and 2 Value levels.
+	Config64Bins = Config{HueLevels: 8, SaturationLevels: 4, ValueLevels: 2}
+
+	// ConfigSmithChang is the 162 bin layout proposed by Smith and Chang:
+	// 18 Hue levels, 3 Saturation levels and 3 Value levels, all of them
+	// equally-sized, with the Version2 Hue quantization.
+	ConfigSmithChang = Config{HueLevels: 18, SaturationLevels: 3, ValueLevels: 3, Version: Version2, EqualSaturation: true}
+
+	// Config32BinsV2 is Config32Bins with the Version2 Hue quantization.
+	Config32BinsV2 = Config{HueLevels: 8, SaturationLevels: 4, ValueLevels: 1, Version: Version2}
+
+	// Config64BinsV2 is Config64Bins with the Version2 Hue quantization.
+	Config64BinsV2 = Config{HueLevels: 8, SaturationLevels: 4, ValueLevels: 2, Version: Version2}
+
+	// Config64BinsGray is Config64BinsV2 with 4 more bins for the colors
+	// whose Saturation or Value are below 10%, divided by their Value.
+	Config64BinsGray = Config{
+		HueLevels: 8, SaturationLevels: 4, ValueLevels: 2, Version: Version2,
+		GrayLevels: 4, GraySaturation: 10, GrayValue: 10,
+	}
+)
+
+// Bins returns the amount of bins in a histogram computed with the Config.
+func (c Config) Bins() int {
+	return c.chromaticBins() + c.GrayLevels
+}
+
+// chromaticBins returns the amount of bins that aren't gray.
+func (c Config) chromaticBins() int {
+	return c.HueLevels * c.SaturationLevels * c.ValueLevels
+}
+
+// valid reports whether every channel is mapped to at least one level
+// and the Hue quantization is known.
+func (c Config) valid() bool {
+
+	if c.Version != Version1 && c.Version != Version2 {
+		return false
+	}
+
+	if c.RedCentered && c.Version != Version2 {
+		return false
+	}
+
+	if c.GrayLevels < 0 || c.GrayLevels == 0 && (c.GraySaturation != 0 || c.GrayValue != 0) {
+		return false
+	}
+
+	return c.HueLevels > 0 && c.SaturationLevels > 0 && c.ValueLevels > 0
+
+}
+
+// Index returns the bin for the given Hue, Saturation and Value levels.
+// A Hue level of HueLevels selects the gray bin of the given Value level.
+func (c Config) Index(hueLevel, saturationLevel, valueLevel int) int {
+
+	if hueLevel == c.HueLevels {
+		return c.chromaticBins() + valueLevel
+	}
+
+	return saturationLevel + c.SaturationLevels*(hueLevel+c.HueLevels*valueLevel)
+
+}
+
+// Levels returns the Hue, Saturation and Value levels of the given bin.
+// It is the inverse of Index.
+func (c Config) Levels(index int) (hueLevel, saturationLevel, valueLevel int) {
+
+	if index >= c.chromaticBins() {
+		return c.HueLevels, 0, index - c.chromaticBins()
+	}
+
+	chromaticBins := c.HueLevels * c.SaturationLevels
+	return (index % chromaticBins) / c.SaturationLevels, index % c.SaturationLevels, index / chromaticBins
+
+}
+
+// Center returns the Hue, in [0,360], the Saturation and the Value, in
+// [0,100], at the center of the levels of the given bin, which the color
+// also maps to. Gray bins have no Hue and no Saturation. With Version1, the
+// last Hue level only holds a Hue of exactly 360°, and without
+// EqualSaturation the last Saturation level only holds a Saturation of
+// exactly 100, which are returned.
+func (c Config) Center(index int) (h, s, v float64) {
+
+	hueLevel, saturationLevel, valueLevel := c.Levels(index)
+	if c.Gray(index) {
+		return 0, 0, center(valueLevel, 0, 100, c.GrayLevels)
+	}
+
+	switch {
+	case c.Version == Version1 && hueLevel == c.HueLevels-1:
+		h = 360
+	case c.Version == Version1:
+		h = center(hueLevel, 0, 360, c.HueLevels-1)
+	case c.RedCentered:
+		h = float64(hueLevel) * 360 / float64(c.HueLevels)
+	default:
+		h = center(hueLevel, 0, 360, c.HueLevels)
+	}
+
+	switch {
+	case c.EqualSaturation:
+		s = center(saturationLevel, 0, 100, c.SaturationLevels)
+	case c.SaturationLevels > 1:
+		s = math.Min(center(saturationLevel, 0, 100, c.SaturationLevels-1), 100)
+	default:
+		s = 50
+	}
+
+	return h, s, center(valueLevel, 0, 100, c.ValueLevels)
+
+}
+
+// Gray reports whether the given bin is one of the gray bins.
+func (c Config) Gray(index int) bool {
+	return index >= c.chromaticBins() && index < c.Bins()
+}
+
+// gray reports whether a color with saturation s and value v is gray.
+func (c Config) gray(s, v float64) bool {
+	return c.GrayLevels > 0 && (s < c.GraySaturation || v < c.GrayValue)
+}
+
+// Bin returns the bin for a color in the RGBA color space.
+func (c Config) Bin(r, g, b, a uint32) int {
+	return c.quantize(c.hsv(r, g, b, a))
+}
+
+// Coordinates returns the position of a color in the RGBA color space along
+// the Hue, Saturation and Value levels. Since the Hue wraps around, 360° is
+// the same as 0°, so with Version1 the last Hue level, which only holds a
+// Hue of exactly 360°, never gets a share of a pixel. Gray colors are only
+// split among the gray bins, never with the other ones.
+func (c Config) Coordinates(r, g, b, a uint32) [3]Coordinate {
+
+	h, s, v := c.hsv(r, g, b, a)
+
+	if c.gray(s, v) {
+		return [3]Coordinate{
+			{Position: float64(c.HueLevels) + 0.5, Levels: c.HueLevels + 1},
+			{Position: 0.5, Levels: 1},
+			{Position: v * float64(c.GrayLevels) / 100, Levels: c.GrayLevels},
+		}
+	}
+
+	return [3]Coordinate{
+		{Position: c.huePosition(h), Levels: c.HuePeriod(), Circular: true},
+		{Position: c.saturationPosition(s), Levels: c.SaturationLevels},
+		{Position: v * float64(c.ValueLevels) / 100, Levels: c.ValueLevels},
+	}
+
+}
+
+// hsv returns the HSV values of a color in the RGBA color space.
+func (c Config) hsv(r, g, b, a uint32) (h, s, v float64) {
+
+	if c.Exact {
+		return conversion.RGBAToHSVExact(r, g, b, a)
+	}
+
+	return conversion.RGBAToHSV(r, g, b, a)
+
+}
+
+// quantize returns the bin for a color with hue h in [0,360],
+// saturation s in [0,100] and value v in [0,100].
+func (c Config) quantize(h, s, v float64) int {
+
+	if c.gray(s, v) {
+		return c.Index(c.HueLevels, 0, valueLevel(v, c.GrayLevels))
+	}
+
+	// hueLevel in [0,HueLevels-1].
+	hueLevel := int(c.huePosition(h))
+	if hueLevel >= c.HueLevels {
+		hueLevel = c.HueLevels - 1
+	}
+
+	// saturationLevel in [0,SaturationLevels-1].
+	saturationLevel := int(c.saturationPosition(s))
+	if saturationLevel >= c.SaturationLevels {
+		saturationLevel = c.SaturationLevels - 1
+	}
+
+	return c.Index(hueLevel, saturationLevel, valueLevel(v, c.ValueLevels))
+
+}
+
+// valueLevel returns the level of value v, in [0,100], in [0,levels-1].
+// Values on the boundary between two levels are mapped to the lower one.
+func valueLevel(v float64, levels int) int {
+
+	level := int(math.Ceil(v*float64(levels)/100)) - 1
+	if level < 0 {
+		return 0
+	}
+
+	return level
+
+}
+
+// HuePeriod returns the amount of different Hue levels around the circle,
+// after which they wrap around. With Version1, it's HueLevels-1, since the
+// last level only holds a Hue of exactly 360°, which is the same as 0°, so
+// that level HueLevels-1 should be treated like level 0.
+func (c Config) HuePeriod() int {
+
+	if c.Version == Version1 && c.HueLevels > 1 {
+		return c.HueLevels - 1
+	}
+
+	return c.HueLevels
+
+}
+
+// saturationPosition returns the position of saturation s, in [0,100],
+// along the Saturation levels, so that level i spans [i, i+1).
+func (c Config) saturationPosition(s float64) float64 {
+
+	if c.EqualSaturation {
+		return s * float64(c.SaturationLevels) / 100
+	}
+
+	return s * float64(c.SaturationLevels-1) / 100
+
+}
+
+// huePosition returns the position of hue h, in [0,360],
+// along the Hue levels, so that level i spans [i, i+1).
+func (c Config) huePosition(h float64) float64 {
+
+	if c.Version == Version1 {
+		return h * float64(c.HueLevels-1) / 360
+	}
+
+	if c.RedCentered {
+		h += 180 / float64(c.HueLevels)
+	}
+
+	return math.Mod(h, 360) * float64(c.HueLevels) / 360
+
+}
@@ -0,0 +1,275 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// redCentered is Config32BinsV2 with the first Hue level centered on red.
+var redCentered = Config{HueLevels: 8, SaturationLevels: 4, ValueLevels: 1, Version: Version2, RedCentered: true}
+
+func TestConfig_quantize(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		h, s, v float64
+		want    int
+	}{
+		{name: "32 bins, black", cfg: Config32Bins, h: 0, s: 0, v: 0, want: 0},
+		{name: "32 bins, #bada55", cfg: Config32Bins, h: 74, s: 61, v: 85, want: 5},
+		{name: "32 bins, hue 360", cfg: Config32Bins, h: 360, s: 100, v: 100, want: 31},
+		{name: "64 bins, value 50", cfg: Config64Bins, h: 74, s: 61, v: 50, want: 5},
+		{name: "64 bins, value 51", cfg: Config64Bins, h: 74, s: 61, v: 51, want: 37},
+		{name: "Smith-Chang, #bada55", cfg: ConfigSmithChang, h: 74, s: 61, v: 85, want: 1 + 3*(3+18*2)},
+		{name: "Smith-Chang, value 33", cfg: ConfigSmithChang, h: 0, s: 100, v: 33, want: 2},
+		{name: "Smith-Chang, value 34", cfg: ConfigSmithChang, h: 0, s: 100, v: 34, want: 2 + 3*18},
+		{name: "Smith-Chang, saturation 33", cfg: ConfigSmithChang, h: 0, s: 33, v: 100, want: 3 * 18 * 2},
+		{name: "Smith-Chang, saturation 34", cfg: ConfigSmithChang, h: 0, s: 34, v: 100, want: 1 + 3*18*2},
+		{name: "Smith-Chang, saturation 67", cfg: ConfigSmithChang, h: 0, s: 67, v: 100, want: 2 + 3*18*2},
+		{name: "Smith-Chang, hue 359", cfg: ConfigSmithChang, h: 359, s: 100, v: 100, want: 2 + 3*(17+18*2)},
+		{name: "Smith-Chang, hue 360", cfg: ConfigSmithChang, h: 360, s: 100, v: 100, want: 2 + 3*18*2},
+		{name: "32 bins v2, hue 44", cfg: Config32BinsV2, h: 44, s: 0, v: 100, want: 0},
+		{name: "32 bins v2, hue 45", cfg: Config32BinsV2, h: 45, s: 0, v: 100, want: 4},
+		{name: "32 bins v2, hue 359", cfg: Config32BinsV2, h: 359, s: 0, v: 100, want: 28},
+		{name: "32 bins v2, hue 360", cfg: Config32BinsV2, h: 360, s: 100, v: 100, want: 3},
+		{name: "Red-centered, hue 338", cfg: redCentered, h: 338, s: 0, v: 100, want: 0},
+		{name: "Red-centered, hue 337", cfg: redCentered, h: 337, s: 0, v: 100, want: 28},
+		{name: "Red-centered, hue 22", cfg: redCentered, h: 22, s: 0, v: 100, want: 0},
+		{name: "Red-centered, hue 23", cfg: redCentered, h: 23, s: 0, v: 100, want: 4},
+		{name: "Red-centered, hue 360", cfg: redCentered, h: 360, s: 0, v: 100, want: 0},
+		{name: "Gray, light gray", cfg: Config64BinsGray, h: 0, s: 5, v: 80, want: 64 + 3},
+		{name: "Gray, black", cfg: Config64BinsGray, h: 200, s: 50, v: 5, want: 64},
+		{name: "Gray, on the thresholds", cfg: Config64BinsGray, h: 0, s: 10, v: 10, want: 0},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			if got := tt.cfg.quantize(tt.h, tt.s, tt.v); got != tt.want {
+				t.Errorf("Config.quantize() = %v, want %v", got, tt.want)
+			}
+
+		})
+
+	}
+
+}
+
+func TestConfig_Levels(t *testing.T) {
+
+	for _, cfg := range []Config{Config32Bins, Config64Bins, ConfigSmithChang, Config64BinsV2, redCentered} {
+
+		for i := 0; i < cfg.Bins(); i++ {
+
+			h, s, v := cfg.Levels(i)
+			if h >= cfg.HueLevels || s >= cfg.SaturationLevels || v >= cfg.ValueLevels {
+				t.Errorf("Config.Levels(%d) = %d %d %d, out of range for %+v", i, h, s, v, cfg)
+			}
+
+			if got := cfg.Index(h, s, v); got != i {
+				t.Errorf("Config.Index(Config.Levels(%d)) = %d for %+v", i, got, cfg)
+			}
+
+		}
+
+	}
+
+}
+
+func TestConfig_Center(t *testing.T) {
+
+	for _, cfg := range []Config{Config32Bins, Config64Bins, ConfigSmithChang, Config64BinsV2, redCentered, Config64BinsGray, {HueLevels: 4, SaturationLevels: 1, ValueLevels: 3}} {
+
+		for i := 0; i < cfg.Bins(); i++ {
+
+			h, s, v := cfg.Center(i)
+			if h < 0 || h > 360 || s < 0 || s > 100 || v < 0 || v > 100 {
+				t.Errorf("Config.Center(%d) = %v %v %v, out of range for %+v", i, h, s, v, cfg)
+			}
+
+			if got := cfg.quantize(h, s, v); got != i {
+				t.Errorf("Config.quantize(Config.Center(%d)) = %d for %+v", i, got, cfg)
+			}
+
+		}
+
+	}
+
+	if h, s, v := Config64BinsGray.Center(Config64BinsGray.Index(Config64BinsGray.HueLevels, 0, 1)); h != 0 || s != 0 || v != 37.5 {
+		t.Errorf("Config.Center() of a gray bin = %v %v %v, want 0 0 37.5", h, s, v)
+	}
+
+}
+
+func TestConfig_Bin(t *testing.T) {
+
+	// #ff0001 has a Hue of 359.76, which is rounded to 360.
+	r, g, b, a := color.RGBA{R: 255, B: 1, A: 255}.RGBA()
+
+	rounded := Config32Bins
+	if got, want := rounded.Bin(r, g, b, a), rounded.Index(7, 3, 0); got != want {
+		t.Errorf("Config.Bin() = %v, want %v", got, want)
+	}
+
+	exact := Config32Bins
+	exact.Exact = true
+	if got, want := exact.Bin(r, g, b, a), exact.Index(6, 3, 0); got != want {
+		t.Errorf("Config.Bin() with Exact = %v, want %v", got, want)
+	}
+
+}
+
+func TestConfigVersion2HueLevels(t *testing.T) {
+
+	for _, cfg := range []Config{Config32BinsV2, redCentered, {HueLevels: 18, SaturationLevels: 1, ValueLevels: 1, Version: Version2}} {
+
+		// Every level should get the same amount of whole degrees.
+		degrees := make([]int, cfg.HueLevels)
+		for h := 0; h < 360; h++ {
+			hueLevel, _, _ := cfg.Levels(cfg.quantize(float64(h), 100, 100))
+			degrees[hueLevel]++
+		}
+
+		for hueLevel, got := range degrees {
+			if want := 360 / cfg.HueLevels; got != want {
+				t.Errorf("%+v: Hue level %d has %d degrees, want %d", cfg, hueLevel, got, want)
+			}
+		}
+
+	}
+
+}
+
+func TestConfigEqualSaturation(t *testing.T) {
+
+	// Every bin of the equally-sized layouts should be reachable.
+	for _, cfg := range []Config{ConfigSmithChang, {HueLevels: 8, SaturationLevels: 4, ValueLevels: 2, Version: Version2, EqualSaturation: true}} {
+
+		pixels := make([]int, cfg.Bins())
+		for h := 0; h < 360; h++ {
+			for s := 0; s <= 100; s++ {
+				for v := 0; v <= 100; v += 5 {
+					pixels[cfg.quantize(float64(h), float64(s), float64(v))]++
+				}
+			}
+		}
+
+		for i, count := range pixels {
+			if count == 0 {
+				t.Errorf("%+v: bin %d is never used", cfg, i)
+			}
+		}
+
+		// Every Saturation level should get the same amount of whole percentage points.
+		points := make([]int, cfg.SaturationLevels)
+		for s := 0; s < 100; s++ {
+			_, saturationLevel, _ := cfg.Levels(cfg.quantize(0, float64(s), 100))
+			points[saturationLevel]++
+		}
+
+		for saturationLevel, got := range points {
+			if want := 100 / cfg.SaturationLevels; got < want || got > want+1 {
+				t.Errorf("%+v: Saturation level %d has %d points, want about %d", cfg, saturationLevel, got, want)
+			}
+		}
+
+	}
+
+}
+
+func TestConfigVersions(t *testing.T) {
+
+	// #ff0001 has a Hue of 359.76, which is rounded to 360.
+	r, g, b, a := color.RGBA{R: 255, B: 1, A: 255}.RGBA()
+
+	exact := Config32BinsV2
+	exact.Exact = true
+
+	exactRedCentered := redCentered
+	exactRedCentered.Exact = true
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want int
+	}{
+		{"Version 1", Config32Bins, Config32Bins.Index(7, 3, 0)},
+		{"Version 2", Config32BinsV2, Config32BinsV2.Index(0, 3, 0)},
+		{"Version 2, exact", exact, exact.Index(7, 3, 0)},
+		{"Red-centered, exact", exactRedCentered, exactRedCentered.Index(0, 3, 0)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cfg.Bin(r, g, b, a); got != tt.want {
+			t.Errorf("%s: Config.Bin() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for cfg, want := range map[Config]int{Config32Bins: 7, Config32BinsV2: 8, redCentered: 8, {HueLevels: 1, SaturationLevels: 1, ValueLevels: 1}: 1} {
+		if got := cfg.HuePeriod(); got != want {
+			t.Errorf("%+v: Config.HuePeriod() = %d, want %d", cfg, got, want)
+		}
+	}
+
+	invalid := []Config{
+		{HueLevels: 8, SaturationLevels: 4, ValueLevels: 1, Version: Version2 + 1},
+		{HueLevels: 8, SaturationLevels: 4, ValueLevels: 1, Version: -1},
+		{HueLevels: 8, SaturationLevels: 4, ValueLevels: 1, RedCentered: true},
+	}
+
+	for _, cfg := range invalid {
+		if _, err := New(uniformImage(color.White), cfg, RoundClosest); err != ErrInvalidLayout {
+			t.Errorf("New() with %+v error = %v, want %v", cfg, err, ErrInvalidLayout)
+		}
+	}
+
+}
+
+func TestConfigGrayBins(t *testing.T) {
+
+	cfg := Config64BinsGray
+	if got, want := cfg.Bins(), 68; got != want {
+		t.Fatalf("Config.Bins() = %d, want %d", got, want)
+	}
+
+	for i := 0; i < cfg.Bins(); i++ {
+
+		h, s, v := cfg.Levels(i)
+		if got := cfg.Index(h, s, v); got != i {
+			t.Errorf("Config.Index(Config.Levels(%d)) = %d", i, got)
+		}
+
+		if gray := cfg.Gray(i); gray != (i >= 64) || gray && (h != cfg.HueLevels || s != 0 || v != i-64) {
+			t.Errorf("Config.Levels(%d) = %d %d %d, Gray() = %v", i, h, s, v, gray)
+		}
+
+	}
+
+	// A mid gray is counted, and rounded, in its own bin.
+	got := must(New(uniformImage(color.Gray{Y: 128}), cfg, RoundClosest)).Percentages()
+	want := make([]float64, cfg.Bins())
+	want[cfg.Index(cfg.HueLevels, 0, 1)] = 100
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("New() of a gray image = %v, want %v", got, want)
+	}
+
+	invalid := []Config{
+		{HueLevels: 8, SaturationLevels: 4, ValueLevels: 1, GrayLevels: -1},
+		{HueLevels: 8, SaturationLevels: 4, ValueLevels: 1, GraySaturation: 10},
+		{HueLevels: 8, SaturationLevels: 4, ValueLevels: 1, GrayValue: 10},
+	}
+
+	for _, cfg := range invalid {
+		if _, err := New(uniformImage(color.White), cfg, RoundClosest); err != ErrInvalidLayout {
+			t.Errorf("New() with %+v error = %v, want %v", cfg, err, ErrInvalidLayout)
+		}
+	}
+
+}
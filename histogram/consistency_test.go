@@ -14,7 +14,7 @@ func TestConsistencyForNonConcurrentAlgorithms(t *testing.T) {
 	tests := []struct {
 		name      string
 		img       image.Image
-		roundType int
+		roundType RoundingMode
 	}{
 		{
 			//Photo by Laura Stanley from Pexels
@@ -80,7 +80,7 @@ func TestConsistencyForConcurrentAlgorithms(t *testing.T) {
 	tests := []struct {
 		name      string
 		img       image.Image
-		roundType int
+		roundType RoundingMode
 	}{
 		{
 			//Photo by Laura Stanley from Pexels
@@ -0,0 +1,226 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"sync"
+)
+
+// Engine computes histograms with a fixed set of goroutines, which is
+// shared by all the images processed at the same time. Unlike the
+// concurrent functions, which start new goroutines on every call, an Engine
+// never runs more than Options.Workers goroutines, no matter how many
+// histograms are requested, and each worker reuses its bins across calls.
+// An Engine is safe for concurrent use by multiple goroutines.
+// Close must be called to stop the workers once the Engine is no longer needed.
+type Engine struct {
+	layout    Layout
+	tiling    Tiling
+	count     counter
+	jobs      chan tileJob
+	closeOnce sync.Once
+}
+
+// tileJob is a tile of an image that has to be counted by a worker.
+type tileJob struct {
+	ctx     context.Context
+	img     image.Image
+	tile    image.Rectangle
+	weights WeightFunc
+	result  *tileResult
+}
+
+// tileResult gathers the counts of all the tiles of a histogram.
+type tileResult struct {
+	mutex   sync.Mutex
+	pending sync.WaitGroup
+	counts  []float64
+	pixels  float64
+	err     error
+}
+
+// add sums the input bins to the counts of the result. If err is not
+// nil, the tile was not counted completely and the result is invalid.
+func (r *tileResult) add(bins []float64, pixels float64, err error) {
+
+	r.mutex.Lock()
+	r.pixels += pixels
+	for i := range bins {
+		r.counts[i] += bins[i]
+	}
+	if err != nil {
+		r.err = err
+	}
+	r.mutex.Unlock()
+
+	r.pending.Done()
+
+}
+
+// NewEngine returns an Engine that computes histograms with the given bin
+// layout, starting the amount of workers and using the tiling, alpha
+// policy and kernel set in opts.
+// If the layout is not valid, ErrInvalidLayout is returned.
+func NewEngine(layout Layout, opts Options) (*Engine, error) {
+
+	if !validLayout(layout) {
+		return nil, ErrInvalidLayout
+	}
+
+	return newEngine(layout, opts, layoutCounter(layout, opts)), nil
+
+}
+
+// newEngine returns an Engine whose workers count pixels with count.
+func newEngine(layout Layout, opts Options, count counter) *Engine {
+
+	engine := &Engine{
+		layout: layout,
+		tiling: opts.Tiling,
+		count:  count,
+		jobs:   make(chan tileJob),
+	}
+
+	for i := 0; i < opts.workers(); i++ {
+		go engine.work()
+	}
+
+	return engine
+
+}
+
+// Layout returns the bin layout of the Engine.
+func (e *Engine) Layout() Layout {
+	return e.layout
+}
+
+// New returns the color Histogram of the input image.
+func (e *Engine) New(img image.Image, rounder Rounder) (Histogram, error) {
+	return e.ForRegionContext(context.Background(), img, img.Bounds(), rounder)
+}
+
+// ForRegion returns the color Histogram of the pixels of the input image
+// that are inside rect, like ForRegion does.
+func (e *Engine) ForRegion(img image.Image, rect image.Rectangle, rounder Rounder) (Histogram, error) {
+	return e.ForRegionContext(context.Background(), img, rect, rounder)
+}
+
+// NewContext returns the color Histogram of the input image. The workers
+// stop counting its pixels as soon as ctx is done, in which case an empty
+// Histogram and ctx.Err() are returned.
+func (e *Engine) NewContext(ctx context.Context, img image.Image, rounder Rounder) (Histogram, error) {
+	return e.ForRegionContext(ctx, img, img.Bounds(), rounder)
+}
+
+// ForRegionContext returns the color Histogram of the pixels of the input
+// image that are inside rect, like ForRegion does. The workers stop counting
+// its pixels as soon as ctx is done, in which case an empty Histogram and
+// ctx.Err() are returned.
+func (e *Engine) ForRegionContext(ctx context.Context, img image.Image, rect image.Rectangle, rounder Rounder) (Histogram, error) {
+	return e.forRegion(ctx, img, rect, nil, rounder)
+}
+
+// NewMasked returns the color Histogram of the pixels of the input image
+// selected by mask, like NewMasked does.
+func (e *Engine) NewMasked(img image.Image, mask Mask, rounder Rounder) (Histogram, error) {
+	return e.NewWeighted(img, WeightFunc(mask), rounder)
+}
+
+// NewMaskedContext returns the color Histogram of the pixels of the input
+// image selected by mask, like NewMasked does. The workers stop counting
+// its pixels as soon as ctx is done, in which case an empty Histogram and
+// ctx.Err() are returned.
+func (e *Engine) NewMaskedContext(ctx context.Context, img image.Image, mask Mask, rounder Rounder) (Histogram, error) {
+	return e.NewWeightedContext(ctx, img, WeightFunc(mask), rounder)
+}
+
+// NewWeighted returns the color Histogram of the input image, where every
+// pixel is counted with the weight given by weights, like NewWeighted does.
+func (e *Engine) NewWeighted(img image.Image, weights WeightFunc, rounder Rounder) (Histogram, error) {
+	return e.forRegion(context.Background(), img, img.Bounds(), weights, rounder)
+}
+
+// NewWeightedContext returns the color Histogram of the input image, where
+// every pixel is counted with the weight given by weights, like NewWeighted
+// does. The workers stop counting its pixels as soon as ctx is done, in which
+// case an empty Histogram and ctx.Err() are returned.
+func (e *Engine) NewWeightedContext(ctx context.Context, img image.Image, weights WeightFunc, rounder Rounder) (Histogram, error) {
+	return e.forRegion(ctx, img, img.Bounds(), weights, rounder)
+}
+
+// forRegion has the workers count the pixels of img in rect, weighted by weights.
+func (e *Engine) forRegion(ctx context.Context, img image.Image, rect image.Rectangle, weights WeightFunc, rounder Rounder) (Histogram, error) {
+
+	if err := validate(e.layout, rounder); err != nil {
+		return Histogram{}, err
+	}
+
+	rect = rect.Intersect(img.Bounds())
+	if rect.Empty() {
+		return Histogram{}, ErrEmptyImage
+	}
+
+	result := &tileResult{counts: make([]float64, e.layout.Bins())}
+
+	var err error
+	for _, tile := range e.tiling.Tiles(rect) {
+		if err = e.send(ctx, tileJob{ctx: ctx, img: img, tile: tile, weights: weights, result: result}); err != nil {
+			break
+		}
+	}
+
+	result.pending.Wait()
+	if err == nil {
+		err = result.err
+	}
+
+	if err != nil {
+		return Histogram{}, err
+	}
+
+	return newCountedHistogram(e.layout, rounder, result.pixels, result.counts)
+
+}
+
+// send gives the job to a free worker, unless ctx is done first.
+func (e *Engine) send(ctx context.Context, job tileJob) error {
+
+	job.result.pending.Add(1)
+	select {
+	case e.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		job.result.pending.Done()
+		return ctx.Err()
+	}
+
+}
+
+// Close stops the workers of the Engine, which must not be used afterwards.
+// Calling Close more than once has no effect.
+func (e *Engine) Close() {
+	e.closeOnce.Do(func() {
+		close(e.jobs)
+	})
+}
+
+// work counts the tiles received by the Engine until it's closed.
+func (e *Engine) work() {
+
+	bins := make([]float64, e.layout.Bins())
+	for job := range e.jobs {
+
+		for i := range bins {
+			bins[i] = 0
+		}
+
+		pixels, err := countContext(job.ctx, e.count, job.img, job.tile, job.weights, bins)
+		job.result.add(bins, pixels, err)
+
+	}
+
+}
@@ -0,0 +1,197 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestEngine returns an Engine for the given layout, failing the test if it can't be created.
+func newTestEngine(t testing.TB, layout Layout, opts Options) *Engine {
+
+	engine, err := NewEngine(layout, opts)
+	if err != nil {
+		t.Fatalf("NewEngine(%+v) error = %v", layout, err)
+	}
+
+	return engine
+
+}
+
+func TestNewEngine(t *testing.T) {
+
+	if engine, err := NewEngine(Config{}, Options{}); engine != nil || err != ErrInvalidLayout {
+		t.Errorf("NewEngine() with an invalid layout = %v, %v, want %v", engine, err, ErrInvalidLayout)
+	}
+
+	engine := newTestEngine(t, ConfigLab64Bins, Options{Workers: 2})
+	defer engine.Close()
+
+	if engine.Layout() != ConfigLab64Bins {
+		t.Errorf("Engine.Layout() = %+v, want %+v", engine.Layout(), ConfigLab64Bins)
+	}
+
+	if _, err := engine.New(redAndBlue(), RoundingMode(9)); err != ErrInvalidRoundingMode {
+		t.Errorf("Engine.New() with an invalid rounding mode error = %v, want %v", err, ErrInvalidRoundingMode)
+	}
+
+	if _, err := engine.ForRegion(redAndBlue(), image.Rect(5, 5, 9, 9), RoundClosest); err != ErrEmptyImage {
+		t.Errorf("Engine.ForRegion() outside of the image error = %v, want %v", err, ErrEmptyImage)
+	}
+
+}
+
+func TestEngine_New(t *testing.T) {
+
+	images := imagesOfEveryType(lobsterCrop())
+
+	engines := map[string]*Engine{
+		"Default":   newTestEngine(t, Config64Bins, Options{}),
+		"One":       newTestEngine(t, Config64Bins, Options{Workers: 1}),
+		"Small":     newTestEngine(t, Config64Bins, Options{Workers: 3, Tiling: Tiling{Size: image.Pt(16, 16)}}),
+		"Grid":      newTestEngine(t, Config64Bins, Options{Workers: 5, Tiling: Tiling{Rows: 3, Columns: 7}}),
+		"LUT":       newTestLUT(t, Config64Bins).NewEngine(Options{Workers: 2}),
+		"LUT tiled": newTestLUT(t, Config64Bins).NewEngine(Options{Tiling: Tiling{Size: image.Pt(100, 1)}}),
+	}
+
+	for engineName, engine := range engines {
+
+		defer engine.Close()
+		for name, img := range images {
+
+			t.Run(engineName+" "+name, func(t *testing.T) {
+
+				want := must(New(img, Config64Bins, RoundLargestRemainder))
+				if got := must(engine.New(img, RoundLargestRemainder)); !reflect.DeepEqual(got.Counts(), want.Counts()) || got.Pixels() != want.Pixels() {
+					t.Errorf("Engine.New()\nGot: %v\nWanted: %v", got.Counts(), want.Counts())
+				}
+
+				rect := img.Bounds().Inset(20)
+				want = must(ForRegion(img, rect, Config64Bins, RoundLargestRemainder))
+				if got := must(engine.ForRegion(img, rect, RoundLargestRemainder)); !reflect.DeepEqual(got.Counts(), want.Counts()) || got.Pixels() != want.Pixels() {
+					t.Errorf("Engine.ForRegion()\nGot: %v\nWanted: %v", got.Counts(), want.Counts())
+				}
+
+			})
+
+		}
+
+	}
+
+}
+
+func TestEngineSharedAcrossGoroutines(t *testing.T) {
+
+	engine := newTestEngine(t, Config32Bins, Options{Workers: 2, Tiling: Tiling{Size: image.Pt(64, 64)}})
+	defer engine.Close()
+
+	images := []image.Image{lobsterCrop(), redAndBlue(), randomImage(image.Rect(-10, 5, 90, 77), 1)}
+	results := make([]Histogram, 4*len(images))
+
+	var wg sync.WaitGroup
+	for i := range results {
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = must(engine.New(images[i%len(images)], RoundClosest))
+		}(i)
+
+	}
+
+	wg.Wait()
+	for i, got := range results {
+		if want := must(New(images[i%len(images)], Config32Bins, RoundClosest)); !reflect.DeepEqual(got, want) {
+			t.Errorf("call %d got %v, want %v", i, got.Counts(), want.Counts())
+		}
+	}
+
+}
+
+func TestEngine_NewContext(t *testing.T) {
+
+	const after = 100000
+
+	engine := newTestEngine(t, Config32Bins, Options{Workers: 2, Tiling: Tiling{Size: image.Pt(250, 250)}})
+	defer engine.Close()
+
+	img := lobsterCrop()
+	got, err := engine.NewContext(context.Background(), img, RoundClosest)
+	if want := must(New(img, Config32Bins, RoundClosest)); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Engine.NewContext() = %v, %v, want %v", got.Counts(), err, want.Counts())
+	}
+
+	canceling, ctx := newCancelingImage(after)
+	got, err = engine.NewContext(ctx, canceling, RoundClosest)
+	if err != context.Canceled || got.Counts() != nil {
+		t.Errorf("Engine.NewContext() = %v, %v, want %v", got.Counts(), err, context.Canceled)
+	}
+
+	// The tiles that were not given to a worker are never counted.
+	if reads, limit := atomic.LoadInt64(&canceling.reads), int64(after+2*250*250); reads > limit {
+		t.Errorf("Engine.NewContext() read %d pixels after being canceled, want at most %d", reads-after, limit-after)
+	}
+
+	// The Engine still works after a canceled call.
+	rect := image.Rect(120, 210, 300, 390)
+	got, err = engine.ForRegionContext(context.Background(), img, rect, RoundClosest)
+	if want := must(ForRegion(img, rect, Config32Bins, RoundClosest)); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Engine.ForRegionContext() = %v, %v, want %v", got.Counts(), err, want.Counts())
+	}
+
+}
+
+func TestEngine_Close(t *testing.T) {
+
+	before := runtime.NumGoroutine()
+
+	engine := newTestEngine(t, Config32Bins, Options{Workers: 10})
+	engine.New(redAndBlue(), RoundClosest)
+	engine.Close()
+	engine.Close()
+
+	// Give the workers some time to stop.
+	for i := 0; i < 1000 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines are still running after Close, want %d", after, before)
+	}
+
+}
+
+func BenchmarkEngine(b *testing.B) {
+
+	img := lobsterCrop()
+	engine := newTestEngine(b, Config32Bins, Options{})
+	defer engine.Close()
+
+	b.Run("Engine", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				engine.New(img, RoundClosest)
+			}
+		})
+	})
+
+	b.Run("NewConcurrent", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				NewConcurrent(img, Config32Bins, RoundClosest)
+			}
+		})
+	})
+
+}
@@ -0,0 +1,145 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+)
+
+// Grid is a spatial color descriptor of an image: the image is divided
+// into a grid of cells and each cell gets its own Histogram, so that
+// two images with the same colors in different places can be told apart.
+type Grid struct {
+
+	// Rows and Columns are the size of the grid.
+	Rows, Columns int
+
+	// Rectangles are the cells of the grid, row by row.
+	// Their edges are spread as evenly as possible.
+	Rectangles []image.Rectangle
+
+	// Cells are the histograms of the cells, row by row.
+	// Cells without pixels to count, like fully transparent ones
+	// with SkipTransparent, have no pixels and zero percentages.
+	Cells []Histogram
+}
+
+// Cell returns the Histogram of the cell at the given row and column.
+func (g Grid) Cell(row, column int) Histogram {
+	return g.Cells[column+g.Columns*row]
+}
+
+// Descriptor returns the percentages of every cell, row by row,
+// concatenated into a single slice of Rows*Columns*Bins values,
+// which can be compared with the functions of the distance package.
+func (g Grid) Descriptor() []float64 {
+
+	var descriptor []float64
+	for _, cell := range g.Cells {
+		descriptor = append(descriptor, cell.percentages...)
+	}
+
+	return descriptor
+
+}
+
+// NewGrid returns the Grid of the input image, dividing it into rows*columns
+// cells whose histograms are computed concurrently, using up to NumCPU
+// goroutines.
+// Cells without pixels, such as when the image has fewer rows or columns
+// of pixels than the grid, have zero percentages, so that the Descriptor
+// always has Rows*Columns*Bins values.
+// Besides the errors described on Histogram, ErrInvalidGrid is returned if
+// rows or columns are not positive, and ErrEmptyImage is only returned if
+// there are no pixels to compute the histogram of any of the cells from.
+func NewGrid(img image.Image, rows, columns int, layout Layout, rounder Rounder) (Grid, error) {
+	return NewGridContext(context.Background(), img, rows, columns, layout, rounder, Options{})
+}
+
+// NewGridContext returns the Grid of the input image, like NewGrid does,
+// using up to opts.Workers goroutines, each one computing a cell at a time.
+// Since the cells are the tiles the work is divided into, opts.Tiling is
+// ignored. The goroutines stop as soon as ctx is done, in which case an
+// empty Grid and ctx.Err() are returned.
+func NewGridContext(ctx context.Context, img image.Image, rows, columns int, layout Layout, rounder Rounder, opts Options) (Grid, error) {
+
+	if err := validate(layout, rounder); err != nil {
+		return Grid{}, err
+	}
+
+	if rows <= 0 || columns <= 0 {
+		return Grid{}, ErrInvalidGrid
+	}
+
+	if err := ctx.Err(); err != nil {
+		return Grid{}, err
+	}
+
+	rectangles := grid(img.Bounds(), rows, columns)
+	workers := opts.workers()
+	if workers > len(rectangles) {
+		workers = len(rectangles)
+	}
+
+	cellChannel := make(chan int, len(rectangles))
+	for i := range rectangles {
+		cellChannel <- i
+	}
+	close(cellChannel)
+
+	cells := make([]Histogram, len(rectangles))
+	errChannel := make(chan error, workers)
+	count := layoutCounter(layout, opts)
+	for i := 0; i < workers; i++ {
+		go calculateCells(ctx, count, cellChannel, img, rectangles, layout, rounder, cells, errChannel)
+	}
+
+	var err error
+	for i := 0; i < workers; i++ {
+		if cellErr := <-errChannel; cellErr != nil {
+			err = cellErr
+		}
+	}
+
+	if err != nil {
+		return Grid{}, err
+	}
+
+	var pixels float64
+	for _, cell := range cells {
+		pixels += cell.pixels
+	}
+
+	if pixels <= 0 {
+		return Grid{}, ErrEmptyImage
+	}
+
+	return Grid{Rows: rows, Columns: columns, Rectangles: rectangles, Cells: cells}, nil
+
+}
+
+// calculateCells computes the histogram of every cell whose index is received
+// from cellChannel, then sends the error that made it stop, if any, to errChannel.
+// The cells without pixels get a Histogram with zero percentages.
+func calculateCells(ctx context.Context, count counter, cellChannel <-chan int, img image.Image, rectangles []image.Rectangle, layout Layout, rounder Rounder, cells []Histogram, errChannel chan<- error) {
+
+	var err error
+	for i := range cellChannel {
+
+		counts := make([]float64, layout.Bins())
+
+		var pixels float64
+		if pixels, err = countContext(ctx, count, img, rectangles[i], nil, counts); err != nil {
+			break
+		}
+
+		cells[i] = newHistogram(layout, rounder, pixels, counts)
+
+	}
+
+	errChannel <- err
+
+}
@@ -0,0 +1,186 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestNewGrid(t *testing.T) {
+
+	// A 6x4 image with a red, a green and a blue column, and a white bottom row.
+	img := image.NewRGBA(image.Rect(-2, 3, 4, 7))
+	colors := []color.Color{
+		color.RGBA{R: 255, A: 255},
+		color.RGBA{G: 255, A: 255},
+		color.RGBA{B: 255, A: 255},
+	}
+
+	for y := 3; y < 7; y++ {
+		for x := -2; x < 4; x++ {
+			img.Set(x, y, colors[(x+2)/2])
+			if y >= 5 {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+
+	got, err := NewGrid(img, 2, 3, Config64BinsGray, RoundClosest)
+	if err != nil {
+		t.Fatalf("NewGrid() error = %v", err)
+	}
+
+	if got.Rows != 2 || got.Columns != 3 || len(got.Cells) != 6 || len(got.Rectangles) != 6 {
+		t.Fatalf("NewGrid() = %+v, want 2 rows and 3 columns", got)
+	}
+
+	white := Config64BinsGray.Bin(color.White.RGBA())
+	for column, c := range colors {
+
+		if cell, bin := got.Cell(0, column), Config64BinsGray.Bin(c.RGBA()); cell.Percentages()[bin] != 100 {
+			t.Errorf("Grid.Cell(0, %d) = %v, want bin %d at 100", column, cell.Percentages(), bin)
+		}
+
+		if cell := got.Cell(1, column); cell.Percentages()[white] != 100 {
+			t.Errorf("Grid.Cell(1, %d) = %v, want bin %d at 100", column, cell.Percentages(), white)
+		}
+
+		if want := image.Rect(-2+2*column, 5, 2*column, 7); got.Rectangles[3+column] != want {
+			t.Errorf("Grid.Rectangles[%d] = %v, want %v", 3+column, got.Rectangles[3+column], want)
+		}
+
+	}
+
+	descriptor := got.Descriptor()
+	if len(descriptor) != 6*Config64BinsGray.Bins() {
+		t.Fatalf("len(Grid.Descriptor()) = %d, want %d", len(descriptor), 6*Config64BinsGray.Bins())
+	}
+
+	for i, cell := range got.Cells {
+		bins := Config64BinsGray.Bins()
+		if !reflect.DeepEqual(descriptor[i*bins:(i+1)*bins], cell.Percentages()) {
+			t.Errorf("Grid.Descriptor() of cell %d = %v, want %v", i, descriptor[i*bins:(i+1)*bins], cell.Percentages())
+		}
+	}
+
+}
+
+func TestNewGridMatchesForRegion(t *testing.T) {
+
+	img := lobsterCrop()
+	for name, opts := range map[string]Options{
+		"Default":    {},
+		"One worker": {Workers: 1},
+		"Alpha":      {Workers: 3, Alpha: SkipTransparent},
+		"Kernel":     {Workers: 2, Kernel: LinearKernel},
+	} {
+
+		got, err := NewGridContext(context.Background(), img, 3, 4, Config32Bins, RoundLargestRemainder, opts)
+		if err != nil {
+			t.Fatalf("%s: NewGridContext() error = %v", name, err)
+		}
+
+		for i, rect := range got.Rectangles {
+
+			want, err := ForRegionContext(context.Background(), img, rect, Config32Bins, RoundLargestRemainder, Options{Workers: 1, Alpha: opts.Alpha, Kernel: opts.Kernel})
+			if err != nil || l1(got.Cells[i].Counts(), want.Counts()) > 1e-9 || !reflect.DeepEqual(got.Cells[i].Percentages(), want.Percentages()) {
+				t.Errorf("%s: cell %d = %v, want ForRegion(%v) = %v, %v", name, i, got.Cells[i].Percentages(), rect, want.Percentages(), err)
+			}
+
+		}
+
+	}
+
+}
+
+func TestNewGridEmptyCells(t *testing.T) {
+
+	// A 4x4 image whose right half is fully transparent.
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 2; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	bins := Config32Bins.Bins()
+	red := Config32Bins.Bin(color.RGBA{R: 255, A: 255}.RGBA())
+	got, err := NewGridContext(context.Background(), img, 2, 2, Config32Bins, RoundClosest, Options{Alpha: SkipTransparent})
+	if err != nil {
+		t.Fatalf("NewGridContext() error = %v", err)
+	}
+
+	if descriptor := got.Descriptor(); len(descriptor) != 4*bins {
+		t.Fatalf("len(Grid.Descriptor()) = %d, want %d", len(descriptor), 4*bins)
+	}
+
+	zeros := make([]float64, bins)
+	for row := 0; row < 2; row++ {
+
+		if left := got.Cell(row, 0); left.Percentages()[red] != 100 {
+			t.Errorf("Grid.Cell(%d, 0) = %v, want bin %d at 100", row, left.Percentages(), red)
+		}
+
+		right := got.Cell(row, 1)
+		if right.Pixels() != 0 || !reflect.DeepEqual(right.Percentages(), zeros) || !reflect.DeepEqual(right.Fractions(), zeros) {
+			t.Errorf("Grid.Cell(%d, 1) = %v with %v pixels, want zero percentages", row, right.Percentages(), right.Pixels())
+		}
+
+	}
+
+	// With more rows than the image, some cells are empty rectangles.
+	got, err = NewGrid(img, 6, 1, Config32Bins, RoundClosest)
+	if err != nil || len(got.Cells) != 6 || len(got.Descriptor()) != 6*bins {
+		t.Fatalf("NewGrid() with 6 rows = %+v, %v, want 6 cells", got, err)
+	}
+
+	for i, cell := range got.Cells {
+
+		empty := got.Rectangles[i].Empty()
+		if empty != (cell.Pixels() == 0) || (empty && !reflect.DeepEqual(cell.Percentages(), zeros)) {
+			t.Errorf("Grid.Cells[%d] of %v = %v with %v pixels", i, got.Rectangles[i], cell.Percentages(), cell.Pixels())
+		}
+
+	}
+
+}
+
+func TestNewGridErrors(t *testing.T) {
+
+	img := productImage()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		img           image.Image
+		rows, columns int
+		layout        Layout
+		want          error
+	}{
+		{"Invalid layout", context.Background(), img, 2, 2, Config{}, ErrInvalidLayout},
+		{"No rows", context.Background(), img, 0, 2, Config32Bins, ErrInvalidGrid},
+		{"Negative columns", context.Background(), img, 2, -1, Config32Bins, ErrInvalidGrid},
+		{"Empty image", context.Background(), image.NewRGBA(image.Rectangle{}), 1, 1, Config32Bins, ErrEmptyImage},
+		{"Canceled", canceled, img, 2, 2, Config32Bins, context.Canceled},
+	}
+
+	for _, tt := range tests {
+		if got, err := NewGridContext(tt.ctx, tt.img, tt.rows, tt.columns, tt.layout, RoundClosest, Options{}); err != tt.want || got.Cells != nil {
+			t.Errorf("%s: NewGridContext() = %+v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+
+	transparent := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	if _, err := NewGridContext(context.Background(), transparent, 2, 2, Config32Bins, RoundClosest, Options{Alpha: SkipTransparent}); err != ErrEmptyImage {
+		t.Errorf("NewGridContext() of a transparent image error = %v, want %v", err, ErrEmptyImage)
+	}
+
+}
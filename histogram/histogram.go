@@ -0,0 +1,482 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"errors"
+	"image"
+)
+
+var (
+	// ErrInvalidLayout is returned when a bin layout is nil or has no bins.
+	ErrInvalidLayout = errors.New("histogram: invalid bin layout")
+
+	// ErrInvalidRoundingMode is returned for unknown rounding modes and nil Rounders.
+	ErrInvalidRoundingMode = errors.New("histogram: invalid rounding mode")
+
+	// ErrEmptyImage is returned when there are no pixels to compute a histogram from.
+	ErrEmptyImage = errors.New("histogram: empty image")
+
+	// ErrInvalidGrid is returned when a grid has no rows or no columns.
+	ErrInvalidGrid = errors.New("histogram: invalid grid")
+)
+
+// Histogram is a color histogram computed with a certain bin layout.
+// Besides the rounded percentages, it keeps track of the raw pixel
+// counts it was computed from, so that histograms computed with
+// different layouts can't be mistaken for one another.
+//
+// The functions and methods that compute a Histogram return an empty one
+// together with ErrInvalidLayout if the layout is not valid, with
+// ErrInvalidRoundingMode if the Rounder is nil or an unknown RoundingMode,
+// or with ErrEmptyImage if there are no pixels to compute it from.
+type Histogram struct {
+	layout      Layout
+	rounder     Rounder
+	pixels      float64
+	counts      []float64
+	percentages []float64
+}
+
+// Layout returns the bin layout the Histogram was computed with.
+func (h Histogram) Layout() Layout {
+	return h.layout
+}
+
+// Rounder returns the Rounder used for the percentages, which is the
+// one the Histogram was computed or last rounded with.
+func (h Histogram) Rounder() Rounder {
+	return h.rounder
+}
+
+// Pixels returns the amount of pixels the Histogram was computed from.
+func (h Histogram) Pixels() float64 {
+	return h.pixels
+}
+
+// Counts returns a copy of the amount of pixels mapped to each bin.
+func (h Histogram) Counts() []float64 {
+	return append([]float64(nil), h.counts...)
+}
+
+// Fractions returns the exact fraction of pixels mapped to each bin.
+// They are all zero if the Histogram has no pixels, like an empty cell of a Grid.
+func (h Histogram) Fractions() []float64 {
+
+	fractions := make([]float64, len(h.counts))
+	if h.pixels <= 0 {
+		return fractions
+	}
+
+	for i, count := range h.counts {
+		fractions[i] = count / h.pixels
+	}
+
+	return fractions
+
+}
+
+// Percentages returns a copy of the rounded percentage of pixels mapped to each bin.
+// Unless the rounding mode is RoundLargestRemainder, bins that only differ by their
+// Value level always sum to the rounded percentage of their sum.
+func (h Histogram) Percentages() []float64 {
+	return append([]float64(nil), h.percentages...)
+}
+
+// Round returns a copy of the Histogram, whose percentages are rounded with
+// the given Rounder instead. Since the pixel counts are kept, any histogram
+// can be rounded again, no matter how it was computed.
+func (h Histogram) Round(rounder Rounder) Histogram {
+	return newHistogram(h.layout, rounder, h.pixels, h.counts)
+}
+
+// Bin returns the rounded percentage of pixels mapped to the given channel
+// levels, in the same order as the layout's Index method: for example, Hue,
+// Saturation and Value levels for histograms computed with a Config.
+// It panics if the levels are out of the range of the layout.
+func (h Histogram) Bin(level1, level2, level3 int) float64 {
+
+	index := h.layout.Index(level1, level2, level3)
+	if index < 0 || index >= len(h.percentages) {
+		panic("histogram: bin levels out of range")
+	}
+
+	if l1, l2, l3 := h.layout.Levels(index); l1 != level1 || l2 != level2 || l3 != level3 {
+		panic("histogram: bin levels out of range")
+	}
+
+	return h.percentages[index]
+
+}
+
+// New returns the color Histogram of the input image, using the given bin
+// layout, such as a Config for the HSV color space or a LabConfig for CIELAB.
+func New(img image.Image, layout Layout, rounder Rounder) (Histogram, error) {
+	return ForRegionWithAlpha(img, img.Bounds(), layout, rounder, nil)
+}
+
+// NewWithAlpha returns the color Histogram of the input image, like New
+// does, weighting every pixel as described by the alpha policy.
+func NewWithAlpha(img image.Image, layout Layout, rounder Rounder, alpha AlphaPolicy) (Histogram, error) {
+	return ForRegionWithAlpha(img, img.Bounds(), layout, rounder, alpha)
+}
+
+// NewConcurrent returns the color Histogram of the input image, using the given
+// bin layout. This concurrent version splits the image into up to NumCPU
+// sub-images, using one goroutine per sub-image.
+func NewConcurrent(img image.Image, layout Layout, rounder Rounder) (Histogram, error) {
+	return NewContext(context.Background(), img, layout, rounder, Options{})
+}
+
+// ForRegion returns the color Histogram of the pixels of the input image
+// that are inside rect, using the given bin layout. Like with SubImage,
+// rect is clipped to the bounds of the image, so the Histogram is the same
+// as the one of img.SubImage(rect), without the need to create it.
+func ForRegion(img image.Image, rect image.Rectangle, layout Layout, rounder Rounder) (Histogram, error) {
+	return ForRegionWithAlpha(img, rect, layout, rounder, nil)
+}
+
+// ForRegionWithAlpha returns the color Histogram of the pixels of the input
+// image that are inside rect, like ForRegion does, weighting every pixel as
+// described by the alpha policy.
+func ForRegionWithAlpha(img image.Image, rect image.Rectangle, layout Layout, rounder Rounder, alpha AlphaPolicy) (Histogram, error) {
+
+	if err := validate(layout, rounder); err != nil {
+		return Histogram{}, err
+	}
+
+	return newSequential(img, rect.Intersect(img.Bounds()), nil, layout, rounder, layoutCounter(layout, Options{Alpha: alpha}))
+
+}
+
+// ForRegionConcurrent returns the color Histogram of the pixels of the input
+// image that are inside rect, like ForRegion does. This concurrent version
+// splits the region into up to NumCPU sub-images, using one goroutine per sub-image.
+func ForRegionConcurrent(img image.Image, rect image.Rectangle, layout Layout, rounder Rounder) (Histogram, error) {
+	return ForRegionContext(context.Background(), img, rect, layout, rounder, Options{})
+}
+
+// NewWithOptions returns the color Histogram of the input image, using the
+// given bin layout. The image is divided into tiles as described by
+// opts.Tiling, which are processed by up to opts.Workers goroutines.
+func NewWithOptions(img image.Image, layout Layout, rounder Rounder, opts Options) (Histogram, error) {
+	return NewContext(context.Background(), img, layout, rounder, opts)
+}
+
+// NewContext returns the color Histogram of the input image, like
+// NewWithOptions does. The goroutines stop as soon as ctx is done,
+// in which case an empty Histogram and ctx.Err() are returned.
+func NewContext(ctx context.Context, img image.Image, layout Layout, rounder Rounder, opts Options) (Histogram, error) {
+	return ForRegionContext(ctx, img, img.Bounds(), layout, rounder, opts)
+}
+
+// ForRegionContext returns the color Histogram of the pixels of the input
+// image that are inside rect, like ForRegion does, using the goroutines and
+// tiling set in opts. The goroutines stop as soon as ctx is done,
+// in which case an empty Histogram and ctx.Err() are returned.
+func ForRegionContext(ctx context.Context, img image.Image, rect image.Rectangle, layout Layout, rounder Rounder, opts Options) (Histogram, error) {
+
+	if err := validate(layout, rounder); err != nil {
+		return Histogram{}, err
+	}
+
+	return newConcurrent(ctx, img, rect.Intersect(img.Bounds()), nil, opts, layout, rounder, layoutCounter(layout, opts))
+
+}
+
+// WithConfig returns a color histogram for the input image, using the
+// given bin layout. The values in the bins will represent the
+// percentage of pixels mapped to a certain Hue, Saturation and Value level.
+// It is VERY IMPORTANT TO NOTICE that the percentages are rounded, so the
+// sum of all percentages may not be equal to 100. Bins that only differ by
+// their Value level will always sum to the rounded percentage of their sum.
+// If the layout or the rounder are not valid, or the image is empty, nil is returned.
+func WithConfig(img image.Image, layout Layout, rounder Rounder) []float64 {
+	histogram, _ := New(img, layout, rounder)
+	return histogram.percentages
+}
+
+// WithConfigConcurrent returns a color histogram for the input image, using
+// the given bin layout. This concurrent version splits the image
+// into up to NumCPU sub-images, using one goroutine per sub-image.
+// The values in the bins will represent the percentage of pixels mapped to a
+// certain Hue, Saturation and Value level.
+// It is VERY IMPORTANT TO NOTICE that the percentages are rounded, so the
+// sum of all percentages may not be equal to 100. Bins that only differ by
+// their Value level will always sum to the rounded percentage of their sum.
+// If the layout or the rounder are not valid, or the image is empty, nil is returned.
+func WithConfigConcurrent(img image.Image, layout Layout, rounder Rounder) []float64 {
+	histogram, _ := NewConcurrent(img, layout, rounder)
+	return histogram.percentages
+}
+
+// validate checks the layout and the Rounder of a Histogram, which
+// must not be nil or a RoundingMode other than the known ones.
+func validate(layout Layout, rounder Rounder) error {
+
+	if !validLayout(layout) {
+		return ErrInvalidLayout
+	}
+
+	if rounder == nil {
+		return ErrInvalidRoundingMode
+	}
+
+	if mode, ok := rounder.(RoundingMode); ok && !mode.Valid() {
+		return ErrInvalidRoundingMode
+	}
+
+	return nil
+
+}
+
+// counter adds the pixels of img in rect, multiplied by their weights, to
+// the given bins, returning the total weight they were counted with.
+// rect must be inside of the bounds of img.
+type counter func(img image.Image, rect image.Rectangle, weights WeightFunc, bins []float64) float64
+
+// layoutCounter returns a counter that maps every pixel to its bin with
+// layout.Bin, weighting it as described by the alpha policy in opts.
+// If opts has a Kernel and the layout is a SoftLayout, the pixels are
+// split among the neighbouring bins instead.
+func layoutCounter(layout Layout, opts Options) counter {
+
+	if soft, ok := layout.(SoftLayout); ok && opts.Kernel != nil {
+		return softCounter(soft, opts.Kernel, opts.Alpha)
+	}
+
+	return pixelCounter(layout.Bin, opts.Alpha)
+
+}
+
+// pixelCounter returns a counter that maps every pixel to its
+// bin with bin, weighting it as described by the alpha policy.
+func pixelCounter(bin func(r, g, b, a uint32) int, alpha AlphaPolicy) counter {
+
+	return func(img image.Image, rect image.Rectangle, weights WeightFunc, bins []float64) float64 {
+
+		if alpha == nil && weights == nil {
+			eachPixel(img, rect, func(r, g, b, a uint32) {
+				bins[bin(r, g, b, a)]++
+			})
+			return float64(rect.Dx() * rect.Dy())
+		}
+
+		return eachWeightedPixel(img, rect, alpha, weights, func(r, g, b, a uint32, weight float64) {
+			bins[bin(r, g, b, a)] += weight
+		})
+
+	}
+
+}
+
+// eachWeightedPixel calls fn with every pixel of img in rect, which must be
+// inside of its bounds, and its weight, given by the product of the alpha
+// policy and of weights, either of which can be nil. The pixels whose weight
+// isn't positive are skipped. The total weight of the pixels is returned.
+func eachWeightedPixel(img image.Image, rect image.Rectangle, alpha AlphaPolicy, weights WeightFunc, fn func(r, g, b, a uint32, weight float64)) float64 {
+
+	// eachPixel reads the pixels row by row, from left to right.
+	var total float64
+	x, y := rect.Min.X, rect.Min.Y
+	eachPixel(img, rect, func(r, g, b, a uint32) {
+
+		weight := 1.0
+		if alpha != nil {
+			weight = alpha(a)
+		}
+
+		if weights != nil && weight > 0 {
+			weight *= weights(x, y)
+		}
+
+		if weight > 0 {
+			fn(r, g, b, a, weight)
+			total += weight
+		}
+
+		if x++; x == rect.Max.X {
+			x, y = rect.Min.X, y+1
+		}
+
+	})
+
+	return total
+
+}
+
+// newSequential counts the pixels of img in rect with count, weighted by weights.
+func newSequential(img image.Image, rect image.Rectangle, weights WeightFunc, layout Layout, rounder Rounder, count counter) (Histogram, error) {
+
+	if rect.Empty() {
+		return Histogram{}, ErrEmptyImage
+	}
+
+	counts := make([]float64, layout.Bins())
+	pixels := count(img, rect, weights, counts)
+
+	return newCountedHistogram(layout, rounder, pixels, counts)
+
+}
+
+// newConcurrent counts the pixels of img in rect with count, weighted by
+// weights, dividing it into tiles that are processed by the amount of
+// goroutines set in opts. The goroutines stop as soon as ctx is done,
+// in which case ctx.Err() is returned.
+func newConcurrent(ctx context.Context, img image.Image, rect image.Rectangle, weights WeightFunc, opts Options, layout Layout, rounder Rounder, count counter) (Histogram, error) {
+
+	counts, pixels, err := countConcurrent(ctx, img, rect, weights, opts, layout.Bins(), count)
+	if err != nil {
+		return Histogram{}, err
+	}
+
+	return newCountedHistogram(layout, rounder, pixels, counts)
+
+}
+
+// countConcurrent counts the pixels of img in rect into binAmt bins with
+// count, like newConcurrent does, returning the bins and their total weight.
+func countConcurrent(ctx context.Context, img image.Image, rect image.Rectangle, weights WeightFunc, opts Options, binAmt int, count counter) ([]float64, float64, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if rect.Empty() {
+		return nil, 0, ErrEmptyImage
+	}
+
+	counts := make([]float64, binAmt)
+	tiles := opts.Tiling.Tiles(rect)
+
+	workers := opts.workers()
+	if workers > len(tiles) {
+		workers = len(tiles)
+	}
+
+	tileChannel := make(chan image.Rectangle, len(tiles))
+	for _, tile := range tiles {
+		tileChannel <- tile
+	}
+	close(tileChannel)
+
+	binChannel := make(chan tileCounts, workers)
+	for i := 0; i < workers; i++ {
+		go calculateBinsForTiles(ctx, len(counts), count, tileChannel, img, weights, binChannel)
+	}
+
+	// Gather the results from all goroutines and sum them.
+	var pixels float64
+	var err error
+	for i := 0; i < workers; i++ {
+
+		currentBins := <-binChannel
+		if currentBins.err != nil {
+			err = currentBins.err
+		}
+
+		pixels += currentBins.pixels
+		for i := range counts {
+			counts[i] += currentBins.bins[i]
+		}
+
+	}
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return counts, pixels, nil
+
+}
+
+// tileCounts are the bins counted by a goroutine and their total
+// weight, with the error that made it stop before counting all of its tiles.
+type tileCounts struct {
+	bins   []float64
+	pixels float64
+	err    error
+}
+
+// calculateBinsForTiles counts the pixels of every tile received from
+// tileChannel, weighted by weights, then sends the bins to outputChan.
+func calculateBinsForTiles(ctx context.Context, binAmt int, count counter, tileChannel <-chan image.Rectangle, img image.Image, weights WeightFunc, outputChan chan<- tileCounts) {
+
+	result := tileCounts{bins: make([]float64, binAmt)}
+	for tile := range tileChannel {
+
+		pixels, err := countContext(ctx, count, img, tile, weights, result.bins)
+		result.pixels += pixels
+		if result.err = err; err != nil {
+			break
+		}
+
+	}
+
+	outputChan <- result
+
+}
+
+// pixelsPerCheck is roughly the amount of pixels counted
+// by countContext between two checks of the context.
+const pixelsPerCheck = 1 << 16
+
+// countContext counts the pixels of img in rect with count, weighted by
+// weights, a few rows at a time, checking whether ctx is done in between.
+// If it is, the rows that are left are not counted and ctx.Err() is returned.
+func countContext(ctx context.Context, count counter, img image.Image, rect image.Rectangle, weights WeightFunc, bins []float64) (float64, error) {
+
+	rows := 1
+	if width := rect.Dx(); width > 0 && width < pixelsPerCheck {
+		rows = pixelsPerCheck / width
+	}
+
+	var pixels float64
+	for y := rect.Min.Y; y < rect.Max.Y; y += rows {
+
+		if err := ctx.Err(); err != nil {
+			return pixels, err
+		}
+
+		band := image.Rect(rect.Min.X, y, rect.Max.X, y+rows).Intersect(rect)
+		pixels += count(img, band, weights, bins)
+
+	}
+
+	return pixels, nil
+
+}
+
+// newCountedHistogram returns a Histogram with the given counts and their
+// percentages, or ErrEmptyImage if no pixel was counted.
+func newCountedHistogram(layout Layout, rounder Rounder, pixels float64, counts []float64) (Histogram, error) {
+
+	if pixels <= 0 {
+		return Histogram{}, ErrEmptyImage
+	}
+
+	return newHistogram(layout, rounder, pixels, counts), nil
+
+}
+
+// newHistogram returns a Histogram with the given counts and their percentages,
+// which are all zero if there are no pixels.
+func newHistogram(layout Layout, rounder Rounder, pixels float64, counts []float64) Histogram {
+
+	percentages := make([]float64, len(counts))
+	if pixels > 0 {
+		percentages = rounder.Round(layout, pixels, counts)
+	}
+
+	return Histogram{
+		layout:      layout,
+		rounder:     rounder,
+		pixels:      pixels,
+		counts:      counts,
+		percentages: percentages,
+	}
+
+}
@@ -0,0 +1,444 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math/rand"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// redAndBlue returns a 4x2 image whose left half is pure red and right half is pure blue.
+func redAndBlue() image.Image {
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			if x < 2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	return img
+
+}
+
+func TestNew(t *testing.T) {
+
+	got := must(New(redAndBlue(), Config64Bins, RoundClosest))
+
+	if got.Layout() != Config64Bins {
+		t.Errorf("New() layout = %+v, want %+v", got.Layout(), Config64Bins)
+	}
+
+	if got.Rounder() != RoundClosest {
+		t.Errorf("New() rounder = %v, want %v", got.Rounder(), RoundClosest)
+	}
+
+	if got.Pixels() != 8 {
+		t.Errorf("New() pixels = %v, want %v", got.Pixels(), 8)
+	}
+
+	wantCounts := make([]float64, 64)
+	wantCounts[Config64Bins.Index(0, 3, 1)] = 4
+	wantCounts[Config64Bins.Index(4, 3, 1)] = 4
+	if !reflect.DeepEqual(got.Counts(), wantCounts) {
+		t.Errorf("New() counts\nGot: %v\nWanted: %v", got.Counts(), wantCounts)
+	}
+
+	if got.Bin(0, 3, 1) != 50 || got.Bin(4, 3, 1) != 50 || got.Bin(0, 3, 0) != 0 {
+		t.Errorf("New() bins = %v", got.Percentages())
+	}
+
+	// The copies must not alias the Histogram.
+	got.Counts()[0] = 100
+	got.Percentages()[0] = 100
+	if got.Counts()[0] != 0 || got.Percentages()[0] != 0 {
+		t.Errorf("New() accessors return the internal slices")
+	}
+
+}
+
+func TestNewWithOffsetBounds(t *testing.T) {
+
+	crop := lobsterCrop()
+	bounds := crop.Bounds()
+
+	// The same pixels, starting from (0,0). RGBA64 keeps the exact colors.
+	moved := image.NewRGBA64(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			moved.Set(x-bounds.Min.X, y-bounds.Min.Y, crop.At(x, y))
+		}
+	}
+
+	for _, layout := range []Layout{Config32Bins, ConfigLab64Bins} {
+
+		got := must(New(crop, layout, RoundClosest))
+		want := must(New(moved, layout, RoundClosest))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("New() %+v\nGot: %v\nWanted: %v", layout, got.Counts(), want.Counts())
+		}
+
+	}
+
+	if got, want := With64Bins(crop, RoundClosest), With64Bins(moved, RoundClosest); !reflect.DeepEqual(got, want) {
+		t.Errorf("With64Bins()\nGot: %v\nWanted: %v", got, want)
+	}
+
+}
+
+func TestForRegion(t *testing.T) {
+
+	img := getImageByRelativePath(`../pictures/lobster_medium.jpg`).(*image.YCbCr)
+	bounds := img.Bounds()
+
+	regions := map[string]image.Rectangle{
+		"Whole image":   bounds,
+		"Crop":          image.Rect(100, 200, 357, 391),
+		"Single pixel":  image.Rect(50, 60, 51, 61),
+		"Partly out":    image.Rect(-20, bounds.Max.Y-40, 90, bounds.Max.Y+40),
+		"Bottom right":  image.Rectangle{Min: bounds.Max.Sub(image.Pt(33, 17)), Max: bounds.Max},
+		"Larger bounds": bounds.Inset(-10),
+	}
+
+	for name, rect := range regions {
+
+		t.Run(name, func(t *testing.T) {
+
+			got := must(ForRegion(img, rect, Config64Bins, RoundClosest))
+			want := must(New(img.SubImage(rect), Config64Bins, RoundClosest))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ForRegion() pixels = %v, want %v\nGot: %v\nWanted: %v", got.Pixels(), want.Pixels(), got.Counts(), want.Counts())
+			}
+
+		})
+
+	}
+
+}
+
+// must returns h, panicking if err is not nil, to keep the tests short.
+func must(h Histogram, err error) Histogram {
+
+	if err != nil {
+		panic(err)
+	}
+
+	return h
+
+}
+
+// randomImage returns an image with the given bounds and random colors.
+func randomImage(bounds image.Rectangle, seed int64) image.Image {
+
+	random := rand.New(rand.NewSource(seed))
+	img := image.NewNRGBA(bounds)
+	random.Read(img.Pix)
+
+	return img
+
+}
+
+func TestConcurrentCountsEveryPixelOnce(t *testing.T) {
+
+	property := func(minX, minY int16, width, height, sizeX, sizeY, rows, columns uint8, seed int64) bool {
+
+		bounds := image.Rect(int(minX), int(minY), int(minX)+int(width)+1, int(minY)+int(height)+1)
+		img := randomImage(bounds, seed)
+		want := must(New(img, Config64Bins, RoundNone))
+
+		if got := must(NewConcurrent(img, Config64Bins, RoundNone)); !reflect.DeepEqual(got, want) {
+			t.Logf("NewConcurrent() of %v\nGot: %v\nWanted: %v", bounds, got.Counts(), want.Counts())
+			return false
+		}
+
+		for name, tiling := range tilings(sizeX, sizeY, rows, columns) {
+			if got := must(NewWithOptions(img, Config64Bins, RoundNone, Options{Workers: 3, Tiling: tiling})); !reflect.DeepEqual(got, want) {
+				t.Logf("NewWithOptions() of %v with %s tiling %+v\nGot: %v\nWanted: %v", bounds, name, tiling, got.Counts(), want.Counts())
+				return false
+			}
+		}
+
+		region := image.Rect(int(minX)+int(sizeX)-32, int(minY)+int(sizeY)-32, int(minX)+int(rows)*2, int(minY)+int(columns)*2)
+		got, err := ForRegionConcurrent(img, region, Config64Bins, RoundNone)
+		want, wantErr := ForRegion(img, region, Config64Bins, RoundNone)
+		if err != wantErr || !reflect.DeepEqual(got, want) {
+			t.Logf("ForRegionConcurrent() of %v in %v\nGot: %v\nWanted: %v", region, bounds, got.Counts(), want.Counts())
+			return false
+		}
+
+		return true
+
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+
+}
+
+// cancelingImage is an image that calls cancel once a certain amount of its pixels has been read.
+type cancelingImage struct {
+	image.Image
+	cancel func()
+	after  int64
+	reads  int64
+}
+
+func (c *cancelingImage) At(x, y int) color.Color {
+
+	if atomic.AddInt64(&c.reads, 1) == c.after {
+		c.cancel()
+	}
+
+	return c.Image.At(x, y)
+
+}
+
+// newCancelingImage returns a large image that cancels the returned context after reading after pixels.
+func newCancelingImage(after int64) (*cancelingImage, context.Context) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	img := &cancelingImage{Image: image.NewRGBA(image.Rect(0, 0, 1000, 1000)), cancel: cancel, after: after}
+
+	return img, ctx
+
+}
+
+func TestNewContext(t *testing.T) {
+
+	img := lobsterCrop()
+	want := must(NewConcurrent(img, Config64Bins, RoundClosest))
+
+	got, err := NewContext(context.Background(), img, Config64Bins, RoundClosest, Options{})
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("NewContext() = %v, %v, want %v", got.Counts(), err, want.Counts())
+	}
+
+	rect := image.Rect(150, 250, 300, 300)
+	got, err = ForRegionContext(context.Background(), img, rect, Config64Bins, RoundClosest, Options{Workers: 2})
+	if want := must(ForRegion(img, rect, Config64Bins, RoundClosest)); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ForRegionContext() = %v, %v, want %v", got.Counts(), err, want.Counts())
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, err := NewContext(canceled, img, Config64Bins, RoundClosest, Options{}); err != context.Canceled || got.Counts() != nil {
+		t.Errorf("NewContext() with a canceled context = %v, %v, want %v", got.Counts(), err, context.Canceled)
+	}
+
+	expired, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-expired.Done()
+	if _, err := ForRegionContext(expired, img, rect, Config64Bins, RoundClosest, Options{}); err != context.DeadlineExceeded {
+		t.Errorf("ForRegionContext() with an expired context error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+}
+
+func TestNewContextCanceledMidComputation(t *testing.T) {
+
+	const after = 100000
+
+	for _, opts := range []Options{{}, {Workers: 4}, {Workers: 3, Tiling: Tiling{Size: image.Pt(100, 100)}}} {
+
+		img, ctx := newCancelingImage(after)
+		got, err := NewContext(ctx, img, Config32Bins, RoundClosest, opts)
+		if err != context.Canceled || got.Counts() != nil {
+			t.Errorf("NewContext() with %+v = %v, %v, want %v", opts, got.Counts(), err, context.Canceled)
+		}
+
+		// Every worker may finish the rows it was counting when the context was canceled.
+		limit := int64(after + opts.workers()*(pixelsPerCheck+img.Bounds().Dx()))
+		if reads := atomic.LoadInt64(&img.reads); reads > limit {
+			t.Errorf("NewContext() with %+v read %d pixels after being canceled, want at most %d", opts, reads-after, limit-after)
+		}
+
+	}
+
+}
+
+func TestCountContextEmptyRectangle(t *testing.T) {
+
+	bins := make([]float64, Config32Bins.Bins())
+	for _, rect := range []image.Rectangle{image.Rect(0, 0, 0, 5), {}} {
+		if pixels, err := countContext(context.Background(), layoutCounter(Config32Bins, Options{}), redAndBlue(), rect, nil, bins); pixels != 0 || err != nil {
+			t.Errorf("countContext() of %v = %v, %v, want no pixels", rect, pixels, err)
+		}
+	}
+
+	if !reflect.DeepEqual(bins, make([]float64, Config32Bins.Bins())) {
+		t.Errorf("countContext() of empty rectangles counted %v", bins)
+	}
+
+}
+
+func TestNewErrors(t *testing.T) {
+
+	img := redAndBlue()
+	empty := image.NewRGBA(image.Rect(4, 4, 4, 9))
+
+	tests := []struct {
+		name string
+		fn   func() (Histogram, error)
+		want error
+	}{
+		{"Invalid layout", func() (Histogram, error) { return New(img, Config{}, RoundClosest) }, ErrInvalidLayout},
+		{"Nil layout", func() (Histogram, error) { return NewConcurrent(img, nil, RoundClosest) }, ErrInvalidLayout},
+		{"Invalid rounding mode", func() (Histogram, error) { return New(img, Config32Bins, RoundingMode(42)) }, ErrInvalidRoundingMode},
+		{"Negative rounding mode", func() (Histogram, error) { return NewConcurrent(img, Config32Bins, RoundingMode(-1)) }, ErrInvalidRoundingMode},
+		{"Nil rounder", func() (Histogram, error) { return ForRegion(img, img.Bounds(), Config32Bins, nil) }, ErrInvalidRoundingMode},
+		{"Empty image", func() (Histogram, error) { return New(empty, Config32Bins, RoundClosest) }, ErrEmptyImage},
+		{"Empty image concurrent", func() (Histogram, error) { return NewConcurrent(empty, Config32Bins, RoundClosest) }, ErrEmptyImage},
+		{"Region outside", func() (Histogram, error) {
+			return ForRegion(img, image.Rect(10, 10, 20, 20), Config32Bins, RoundClosest)
+		}, ErrEmptyImage},
+		{"Region outside concurrent", func() (Histogram, error) {
+			return ForRegionConcurrent(img, image.Rect(-5, 0, 0, 2), Config32Bins, RoundClosest)
+		}, ErrEmptyImage},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := tt.fn()
+			if err != tt.want || got.Layout() != nil || got.Counts() != nil {
+				t.Errorf("error = %v, want %v, with an empty Histogram instead of %+v", err, tt.want, got)
+			}
+
+		})
+
+	}
+
+	if got := WithConfig(empty, Config32Bins, RoundClosest); got != nil {
+		t.Errorf("WithConfig() with an empty image = %v, want nil", got)
+	}
+
+	if got := WithConfigConcurrent(img, Config32Bins, RoundingMode(42)); got != nil {
+		t.Errorf("WithConfigConcurrent() with an invalid rounding mode = %v, want nil", got)
+	}
+
+}
+
+func TestHistogram_Bin(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Histogram.Bin() with out of range levels did not panic")
+		}
+	}()
+
+	must(New(redAndBlue(), Config32Bins, RoundClosest)).Bin(0, 0, 1)
+
+}
+
+func TestWithConfig(t *testing.T) {
+
+	img := redAndBlue()
+
+	want := make([]float64, ConfigSmithChang.Bins())
+	want[ConfigSmithChang.quantize(0, 100, 100)] = 50
+	want[ConfigSmithChang.quantize(240, 100, 100)] = 50
+
+	if got := WithConfig(img, ConfigSmithChang, RoundClosest); !reflect.DeepEqual(got, want) {
+		t.Errorf("WithConfig()\nGot: %v\nWanted: %v", got, want)
+	}
+
+	if got := WithConfig(img, Config{HueLevels: 8, SaturationLevels: 0, ValueLevels: 1}, RoundClosest); got != nil {
+		t.Errorf("WithConfig() with invalid config = %v, want nil", got)
+	}
+
+}
+
+func TestWithConfigConcurrent(t *testing.T) {
+
+	img := getImageByRelativePath(`../pictures/lobster_medium.jpg`)
+
+	for _, cfg := range []Config{Config32Bins, Config64Bins, ConfigSmithChang} {
+
+		sequential := WithConfig(img, cfg, RoundClosest)
+		concurrent := WithConfigConcurrent(img, cfg, RoundClosest)
+
+		if !reflect.DeepEqual(sequential, concurrent) {
+			t.Errorf("WithConfigConcurrent() %+v\nGot: %v\nWanted: %v", cfg, concurrent, sequential)
+		}
+
+	}
+
+}
+
+func TestNewRoundingModes(t *testing.T) {
+
+	// Three pixels of different colors, one third each.
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	img.Set(1, 0, color.RGBA{G: 255, A: 255})
+	img.Set(2, 0, color.RGBA{B: 255, A: 255})
+
+	red := Config32Bins.quantize(0, 100, 100)
+	green := Config32Bins.quantize(120, 100, 100)
+	blue := Config32Bins.quantize(240, 100, 100)
+
+	tests := []struct {
+		name      string
+		roundType RoundingMode
+		want      [3]float64
+	}{
+		{name: "RoundClosest", roundType: RoundClosest, want: [3]float64{33, 33, 33}},
+		{name: "RoundUp", roundType: RoundUp, want: [3]float64{34, 34, 34}},
+		{name: "RoundDown", roundType: RoundDown, want: [3]float64{33, 33, 33}},
+		{name: "RoundNone", roundType: RoundNone, want: [3]float64{100.0 / 3, 100.0 / 3, 100.0 / 3}},
+		{name: "RoundLargestRemainder", roundType: RoundLargestRemainder, want: [3]float64{34, 33, 33}},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			got := must(New(img, Config32Bins, tt.roundType)).Percentages()
+			if got[red] != tt.want[0] || got[green] != tt.want[1] || got[blue] != tt.want[2] {
+				t.Errorf("New() = %v, want %v", got, tt.want)
+			}
+
+		})
+
+	}
+
+	fractions := must(New(img, Config32Bins, RoundClosest)).Fractions()
+	if fractions[red] != 1.0/3 || fractions[green] != 1.0/3 || fractions[blue] != 1.0/3 {
+		t.Errorf("Histogram.Fractions() = %v", fractions)
+	}
+
+}
+
+func TestRoundLargestRemainderSumsTo100(t *testing.T) {
+
+	img := getImageByRelativePath(`../pictures/beach_medium.jpg`)
+
+	for _, cfg := range []Config{Config32Bins, Config64Bins, ConfigSmithChang} {
+
+		var sum float64
+		for _, percentage := range must(New(img, cfg, RoundLargestRemainder)).Percentages() {
+			sum += percentage
+		}
+
+		if sum != 100 {
+			t.Errorf("New() with RoundLargestRemainder for %+v sums to %v, want 100", cfg, sum)
+		}
+
+	}
+
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"github.com/AlessandroPomponio/hsv/conversion"
+)
+
+// The ranges of the CIELAB and LCh channels for colors in the sRGB gamut.
+const (
+	maxLightness = 100
+	minAB        = -128
+	maxAB        = 128
+	maxChroma    = 134
+)
+
+// LabConfig describes how the CIELAB color space is quantized into bins.
+// Each channel is divided into the given amount of equally-sized intervals
+// and the bin index is computed as
+// bLevel + BLevels*(aLevel + ALevels*lightnessLevel).
+type LabConfig struct {
+
+	// LightnessLevels is the amount of levels L, in [0,100], is mapped to.
+	LightnessLevels int
+
+	// ALevels is the amount of levels a, in [-128,128), is mapped to.
+	ALevels int
+
+	// BLevels is the amount of levels b, in [-128,128), is mapped to.
+	BLevels int
+}
+
+// LChConfig describes how the LCh(ab) color space, the cylindrical
+// representation of CIELAB, is quantized into bins.
+// Each channel is divided into the given amount of equally-sized intervals
+// and the bin index is computed as
+// chromaLevel + ChromaLevels*(hueLevel + HueLevels*lightnessLevel),
+// mirroring the layout of the HSV histograms.
+type LChConfig struct {
+
+	// LightnessLevels is the amount of levels L, in [0,100], is mapped to.
+	LightnessLevels int
+
+	// ChromaLevels is the amount of levels C, in [0,134), is mapped to.
+	// 134 is just above the highest chroma of the sRGB gamut.
+	ChromaLevels int
+
+	// HueLevels is the amount of levels h, in [0,360), is mapped to.
+	HueLevels int
+}
+
+var (
+	// ConfigLab64Bins maps each CIELAB channel to 4 levels.
+	ConfigLab64Bins = LabConfig{LightnessLevels: 4, ALevels: 4, BLevels: 4}
+
+	// ConfigLCh64Bins maps L to 2 levels, C to 4 levels and h to 8 levels.
+	ConfigLCh64Bins = LChConfig{LightnessLevels: 2, ChromaLevels: 4, HueLevels: 8}
+)
+
+// Bins returns the amount of bins in a histogram computed with the LabConfig.
+func (c LabConfig) Bins() int {
+
+	if c.LightnessLevels <= 0 || c.ALevels <= 0 || c.BLevels <= 0 {
+		return 0
+	}
+
+	return c.LightnessLevels * c.ALevels * c.BLevels
+
+}
+
+// Bin returns the bin for a color in the RGBA color space.
+func (c LabConfig) Bin(r, g, b, a uint32) int {
+
+	lightness, aValue, bValue := conversion.RGBAToLab(r, g, b, a)
+	return c.Index(
+		level(lightness, 0, maxLightness, c.LightnessLevels),
+		level(aValue, minAB, maxAB, c.ALevels),
+		level(bValue, minAB, maxAB, c.BLevels),
+	)
+
+}
+
+// Coordinates returns the position of a color in the
+// RGBA color space along the L, a and b levels.
+func (c LabConfig) Coordinates(r, g, b, a uint32) [3]Coordinate {
+
+	lightness, aValue, bValue := conversion.RGBAToLab(r, g, b, a)
+	return [3]Coordinate{
+		{Position: position(lightness, 0, maxLightness, c.LightnessLevels), Levels: c.LightnessLevels},
+		{Position: position(aValue, minAB, maxAB, c.ALevels), Levels: c.ALevels},
+		{Position: position(bValue, minAB, maxAB, c.BLevels), Levels: c.BLevels},
+	}
+
+}
+
+// Index returns the bin for the given L, a and b levels.
+func (c LabConfig) Index(lightnessLevel, aLevel, bLevel int) int {
+	return bLevel + c.BLevels*(aLevel+c.ALevels*lightnessLevel)
+}
+
+// Levels returns the L, a and b levels of the given bin.
+// It is the inverse of Index.
+func (c LabConfig) Levels(index int) (lightnessLevel, aLevel, bLevel int) {
+
+	chromaticBins := c.ALevels * c.BLevels
+	return index / chromaticBins, (index % chromaticBins) / c.BLevels, index % c.BLevels
+
+}
+
+// Bins returns the amount of bins in a histogram computed with the LChConfig.
+func (c LChConfig) Bins() int {
+
+	if c.LightnessLevels <= 0 || c.ChromaLevels <= 0 || c.HueLevels <= 0 {
+		return 0
+	}
+
+	return c.LightnessLevels * c.ChromaLevels * c.HueLevels
+
+}
+
+// Bin returns the bin for a color in the RGBA color space.
+func (c LChConfig) Bin(r, g, b, a uint32) int {
+
+	lightness, chroma, hue := conversion.RGBAToLCh(r, g, b, a)
+	return c.Index(
+		level(lightness, 0, maxLightness, c.LightnessLevels),
+		level(chroma, 0, maxChroma, c.ChromaLevels),
+		level(hue, 0, 360, c.HueLevels),
+	)
+
+}
+
+// Coordinates returns the position of a color in the RGBA color
+// space along the L, C and h levels, the last of which wraps around.
+func (c LChConfig) Coordinates(r, g, b, a uint32) [3]Coordinate {
+
+	lightness, chroma, hue := conversion.RGBAToLCh(r, g, b, a)
+	return [3]Coordinate{
+		{Position: position(lightness, 0, maxLightness, c.LightnessLevels), Levels: c.LightnessLevels},
+		{Position: position(chroma, 0, maxChroma, c.ChromaLevels), Levels: c.ChromaLevels},
+		{Position: position(hue, 0, 360, c.HueLevels), Levels: c.HueLevels, Circular: true},
+	}
+
+}
+
+// Index returns the bin for the given L, C and h levels.
+func (c LChConfig) Index(lightnessLevel, chromaLevel, hueLevel int) int {
+	return chromaLevel + c.ChromaLevels*(hueLevel+c.HueLevels*lightnessLevel)
+}
+
+// Levels returns the L, C and h levels of the given bin.
+// It is the inverse of Index.
+func (c LChConfig) Levels(index int) (lightnessLevel, chromaLevel, hueLevel int) {
+
+	chromaticBins := c.ChromaLevels * c.HueLevels
+	return index / chromaticBins, index % c.ChromaLevels, (index % chromaticBins) / c.ChromaLevels
+
+}
+
+// level maps x, in [minValue,maxValue), to one of the given amount of
+// equally-sized levels. Values out of range are mapped to the closest level.
+func level(x, minValue, maxValue float64, levels int) int {
+
+	l := int(position(x, minValue, maxValue, levels))
+
+	if l < 0 {
+		return 0
+	}
+
+	if l >= levels {
+		return levels - 1
+	}
+
+	return l
+
+}
+
+// position returns the position of x, in [minValue,maxValue), along the given
+// amount of equally-sized levels, so that level i spans [i, i+1).
+func position(x, minValue, maxValue float64, levels int) float64 {
+	return (x - minValue) * float64(levels) / (maxValue - minValue)
+}
+
+// center returns the value at the center of the given level, in
+// [0,levels), of [minValue,maxValue). It is the inverse of position.
+func center(level int, minValue, maxValue float64, levels int) float64 {
+	return minValue + (float64(level)+0.5)*(maxValue-minValue)/float64(levels)
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestLabConfig_Bin(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		c     color.RGBA
+		wantL int
+		wantA int
+		wantB int
+	}{
+		// L: 0, a: 0, b: 0
+		{name: "Black", c: color.RGBA{A: 255}, wantL: 0, wantA: 2, wantB: 2},
+		// L: 100, a: 0, b: 0
+		{name: "White", c: color.RGBA{R: 255, G: 255, B: 255, A: 255}, wantL: 3, wantA: 2, wantB: 2},
+		// L: 53.24, a: 80.09, b: 67.20
+		{name: "Red", c: color.RGBA{R: 255, A: 255}, wantL: 2, wantA: 3, wantB: 3},
+		// L: 32.30, a: 79.19, b: -107.86
+		{name: "Blue", c: color.RGBA{B: 255, A: 255}, wantL: 1, wantA: 3, wantB: 0},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			got := ConfigLab64Bins.Bin(tt.c.RGBA())
+			if want := ConfigLab64Bins.Index(tt.wantL, tt.wantA, tt.wantB); got != want {
+				l, a, b := ConfigLab64Bins.Levels(got)
+				t.Errorf("LabConfig.Bin() = %v (%d %d %d), want %v", got, l, a, b, want)
+			}
+
+		})
+
+	}
+
+}
+
+func TestLChConfig_Bin(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		c          color.RGBA
+		wantL      int
+		wantChroma int
+		wantHue    int
+	}{
+		// L: 0, C: 0, h: 0
+		{name: "Black", c: color.RGBA{A: 255}, wantL: 0, wantChroma: 0, wantHue: 0},
+		// L: 53.24, C: 104.55, h: 40
+		{name: "Red", c: color.RGBA{R: 255, A: 255}, wantL: 1, wantChroma: 3, wantHue: 0},
+		// L: 87.73, C: 119.78, h: 136.02
+		{name: "Green", c: color.RGBA{G: 255, A: 255}, wantL: 1, wantChroma: 3, wantHue: 3},
+		// L: 32.30, C: 133.81, h: 306.28
+		{name: "Blue", c: color.RGBA{B: 255, A: 255}, wantL: 0, wantChroma: 3, wantHue: 6},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			got := ConfigLCh64Bins.Bin(tt.c.RGBA())
+			if want := ConfigLCh64Bins.Index(tt.wantL, tt.wantChroma, tt.wantHue); got != want {
+				l, c, h := ConfigLCh64Bins.Levels(got)
+				t.Errorf("LChConfig.Bin() = %v (%d %d %d), want %v", got, l, c, h, want)
+			}
+
+		})
+
+	}
+
+}
+
+func TestLabAndLChLevels(t *testing.T) {
+
+	for _, layout := range []Layout{ConfigLab64Bins, ConfigLCh64Bins, LabConfig{5, 3, 7}, LChConfig{3, 5, 12}} {
+
+		for i := 0; i < layout.Bins(); i++ {
+
+			l1, l2, l3 := layout.Levels(i)
+			if got := layout.Index(l1, l2, l3); got != i {
+				t.Errorf("Index(Levels(%d)) = %d for %+v", i, got, layout)
+			}
+
+		}
+
+	}
+
+}
+
+func TestNewWithLab(t *testing.T) {
+
+	got := must(New(redAndBlue(), ConfigLCh64Bins, RoundClosest))
+	if got.Layout() != ConfigLCh64Bins {
+		t.Errorf("New() layout = %+v, want %+v", got.Layout(), ConfigLCh64Bins)
+	}
+
+	if got.Bin(1, 3, 0) != 50 || got.Bin(0, 3, 6) != 50 {
+		t.Errorf("New() = %v", got.Percentages())
+	}
+
+	if _, err := New(redAndBlue(), LabConfig{LightnessLevels: 4}, RoundClosest); err != ErrInvalidLayout {
+		t.Errorf("New() with invalid layout error = %v, want %v", err, ErrInvalidLayout)
+	}
+
+	img := getImageByRelativePath(`../pictures/lobster_medium.jpg`)
+	for _, layout := range []Layout{ConfigLab64Bins, ConfigLCh64Bins} {
+
+		sequential := WithConfig(img, layout, RoundClosest)
+		concurrent := WithConfigConcurrent(img, layout, RoundClosest)
+
+		if !reflect.DeepEqual(sequential, concurrent) {
+			t.Errorf("WithConfigConcurrent() %+v\nGot: %v\nWanted: %v", layout, concurrent, sequential)
+		}
+
+	}
+
+}
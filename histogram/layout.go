@@ -0,0 +1,39 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+// Layout describes how the colors of an image are mapped to the bins of a
+// histogram. Every Layout splits the three channels of a color space into a
+// number of levels: Config does so in the HSV color space, while LabConfig
+// and LChConfig do so in the perceptually uniform CIELAB and LCh spaces.
+// Layouts must be comparable, so that histograms computed with different
+// layouts can be told apart.
+type Layout interface {
+
+	// Bins returns the amount of bins in the layout.
+	Bins() int
+
+	// Bin returns the bin for a color, expressed with the
+	// alpha-premultiplied RGBA components returned by color.Color.
+	Bin(r, g, b, a uint32) int
+
+	// Index returns the bin for the given levels of the three channels.
+	Index(level1, level2, level3 int) int
+
+	// Levels returns the levels of the three channels for the given bin.
+	// It is the inverse of Index.
+	Levels(index int) (level1, level2, level3 int)
+}
+
+// validLayout reports whether the layout can be used to compute a histogram.
+func validLayout(layout Layout) bool {
+
+	if cfg, ok := layout.(Config); ok {
+		return cfg.valid()
+	}
+
+	return layout != nil && layout.Bins() > 0
+
+}
@@ -0,0 +1,295 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"runtime"
+	"sync"
+)
+
+// maxLUTBins is the largest amount of bins a LUT can map colors to.
+const maxLUTBins = 1 << 16
+
+// LUT computes histograms with precomputed tables that map every 8-bit color
+// to its bin, removing all floating point math from the per-pixel loop.
+// The tables are built lazily, the first time they are needed, and take
+// 32 MiB each: one for 8-bit RGB colors, used for opaque pixels, and one for
+// the Y'CbCr colors of *image.YCbCr images, such as decoded JPEGs.
+// Pixels that can't be looked up, such as translucent ones, are mapped with
+// the layout's Bin method, so the histograms are identical to the ones
+// returned by New and NewConcurrent.
+// A LUT is safe for concurrent use by multiple goroutines and should be
+// reused: building its tables is much slower than computing a histogram.
+type LUT struct {
+	layout Layout
+
+	rgbOnce sync.Once
+	rgb     []uint16
+
+	ycbcrOnce sync.Once
+	ycbcr     []uint16
+}
+
+// NewLUT returns a LUT for the given bin layout.
+// ErrInvalidLayout is returned if the layout is not valid,
+// or if it has more than 65536 bins.
+func NewLUT(layout Layout) (*LUT, error) {
+
+	if !validLayout(layout) {
+		return nil, ErrInvalidLayout
+	}
+
+	if layout.Bins() > maxLUTBins {
+		return nil, fmt.Errorf("%w: a LUT can't have more than %d bins", ErrInvalidLayout, maxLUTBins)
+	}
+
+	return &LUT{layout: layout}, nil
+
+}
+
+// Layout returns the bin layout of the LUT.
+func (l *LUT) Layout() Layout {
+	return l.layout
+}
+
+// New returns the color Histogram of the input image, like New does.
+func (l *LUT) New(img image.Image, rounder Rounder) (Histogram, error) {
+	return l.ForRegionWithAlpha(img, img.Bounds(), rounder, nil)
+}
+
+// NewWithAlpha returns the color Histogram of the input image, like
+// NewWithAlpha does.
+func (l *LUT) NewWithAlpha(img image.Image, rounder Rounder, alpha AlphaPolicy) (Histogram, error) {
+	return l.ForRegionWithAlpha(img, img.Bounds(), rounder, alpha)
+}
+
+// NewConcurrent returns the color Histogram of the input image, like
+// NewConcurrent does, splitting the image into up to NumCPU sub-images.
+func (l *LUT) NewConcurrent(img image.Image, rounder Rounder) (Histogram, error) {
+	return l.ForRegionConcurrent(img, img.Bounds(), rounder)
+}
+
+// ForRegion returns the color Histogram of the pixels of the input image
+// that are inside rect, like ForRegion does.
+func (l *LUT) ForRegion(img image.Image, rect image.Rectangle, rounder Rounder) (Histogram, error) {
+	return l.ForRegionWithAlpha(img, rect, rounder, nil)
+}
+
+// ForRegionWithAlpha returns the color Histogram of the pixels of the input
+// image that are inside rect, like ForRegionWithAlpha does.
+func (l *LUT) ForRegionWithAlpha(img image.Image, rect image.Rectangle, rounder Rounder, alpha AlphaPolicy) (Histogram, error) {
+
+	if err := validate(l.layout, rounder); err != nil {
+		return Histogram{}, err
+	}
+
+	return newSequential(img, rect.Intersect(img.Bounds()), nil, l.layout, rounder, l.counter(Options{Alpha: alpha}))
+
+}
+
+// ForRegionConcurrent returns the color Histogram of the pixels of the input
+// image that are inside rect, like ForRegionConcurrent does.
+func (l *LUT) ForRegionConcurrent(img image.Image, rect image.Rectangle, rounder Rounder) (Histogram, error) {
+
+	if err := validate(l.layout, rounder); err != nil {
+		return Histogram{}, err
+	}
+
+	return newConcurrent(context.Background(), img, rect.Intersect(img.Bounds()), nil, Options{}, l.layout, rounder, l.counter(Options{}))
+
+}
+
+// NewMasked returns the color Histogram of the pixels of the input image
+// selected by mask, like NewMasked does.
+func (l *LUT) NewMasked(img image.Image, mask Mask, rounder Rounder) (Histogram, error) {
+	return l.NewWeighted(img, WeightFunc(mask), rounder)
+}
+
+// NewMaskedWithAlpha returns the color Histogram of the pixels of the input
+// image selected by mask, like NewMaskedWithAlpha does.
+func (l *LUT) NewMaskedWithAlpha(img image.Image, mask Mask, rounder Rounder, alpha AlphaPolicy) (Histogram, error) {
+	return l.NewWeightedWithAlpha(img, WeightFunc(mask), rounder, alpha)
+}
+
+// NewMaskedConcurrent returns the color Histogram of the pixels of the input
+// image selected by mask, like NewMaskedConcurrent does.
+func (l *LUT) NewMaskedConcurrent(img image.Image, mask Mask, rounder Rounder) (Histogram, error) {
+	return l.NewWeightedConcurrent(img, WeightFunc(mask), rounder)
+}
+
+// NewWeighted returns the color Histogram of the input image, where every
+// pixel is counted with the weight given by weights, like NewWeighted does.
+func (l *LUT) NewWeighted(img image.Image, weights WeightFunc, rounder Rounder) (Histogram, error) {
+	return l.NewWeightedWithAlpha(img, weights, rounder, nil)
+}
+
+// NewWeightedWithAlpha returns the color Histogram of the input image, where
+// every pixel is counted with the weight given by weights, like
+// NewWeightedWithAlpha does.
+func (l *LUT) NewWeightedWithAlpha(img image.Image, weights WeightFunc, rounder Rounder, alpha AlphaPolicy) (Histogram, error) {
+
+	if err := validate(l.layout, rounder); err != nil {
+		return Histogram{}, err
+	}
+
+	return newSequential(img, img.Bounds(), weights, l.layout, rounder, l.counter(Options{Alpha: alpha}))
+
+}
+
+// NewWeightedConcurrent returns the color Histogram of the input image, where
+// every pixel is counted with the weight given by weights, like
+// NewWeightedConcurrent does.
+func (l *LUT) NewWeightedConcurrent(img image.Image, weights WeightFunc, rounder Rounder) (Histogram, error) {
+
+	if err := validate(l.layout, rounder); err != nil {
+		return Histogram{}, err
+	}
+
+	return newConcurrent(context.Background(), img, img.Bounds(), weights, Options{}, l.layout, rounder, l.counter(Options{}))
+
+}
+
+// NewEngine returns an Engine that computes histograms with the LUT,
+// starting the amount of workers and using the tiling, alpha policy and
+// kernel set in opts.
+func (l *LUT) NewEngine(opts Options) *Engine {
+	return newEngine(l.layout, opts, l.counter(opts))
+}
+
+// counter returns the counter used by the LUT, which weights every pixel
+// as described by the alpha policy in opts. Since the tables map each color
+// to a single bin, pixels are split among bins by the layout if opts has a Kernel.
+func (l *LUT) counter(opts Options) counter {
+
+	if _, ok := l.layout.(SoftLayout); ok && opts.Kernel != nil {
+		return layoutCounter(l.layout, opts)
+	}
+
+	alpha := opts.Alpha
+	return func(img image.Image, rect image.Rectangle, weights WeightFunc, bins []float64) float64 {
+
+		if ycbcr, ok := img.(*image.YCbCr); ok && rect.In(img.Bounds()) {
+			return l.countYCbCr(ycbcr, rect, weights, bins, alpha)
+		}
+
+		table := l.rgbTable()
+		return pixelCounter(func(r, g, b, a uint32) int {
+
+			// Only opaque pixels with 8-bit components are in the table.
+			if a == 0xffff && r%0x101 == 0 && g%0x101 == 0 && b%0x101 == 0 {
+				return int(table[(r>>8)<<16|(g>>8)<<8|b>>8])
+			}
+
+			return l.layout.Bin(r, g, b, a)
+
+		}, alpha)(img, rect, weights, bins)
+
+	}
+
+}
+
+// countYCbCr looks up the pixels of img in rect, weighted by weights, where
+// rect must be inside of its bounds. Since Y'CbCr images are opaque, all pixels
+// get the same weight from the alpha policy.
+func (l *LUT) countYCbCr(img *image.YCbCr, rect image.Rectangle, weights WeightFunc, bins []float64, alpha AlphaPolicy) float64 {
+
+	weight := 1.0
+	if alpha != nil {
+		weight = alpha(0xffff)
+	}
+
+	if weight <= 0 {
+		return 0
+	}
+
+	var total float64
+	table := l.ycbcrTable()
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+
+			pixelWeight := weight
+			if weights != nil {
+				if pixelWeight *= weights(x, y); pixelWeight <= 0 {
+					continue
+				}
+			}
+
+			yi, ci := img.YOffset(x, y), img.COffset(x, y)
+			bins[table[int(img.Y[yi])<<16|int(img.Cb[ci])<<8|int(img.Cr[ci])]] += pixelWeight
+			total += pixelWeight
+
+		}
+
+	}
+
+	return total
+
+}
+
+// rgbTable returns the table indexed by R<<16 | G<<8 | B, building it if needed.
+func (l *LUT) rgbTable() []uint16 {
+
+	l.rgbOnce.Do(func() {
+		l.rgb = buildTable(func(c1, c2, c3 uint8) int {
+			return l.layout.Bin(color.RGBA{R: c1, G: c2, B: c3, A: 0xff}.RGBA())
+		})
+	})
+
+	return l.rgb
+
+}
+
+// ycbcrTable returns the table indexed by Y<<16 | Cb<<8 | Cr, building it if needed.
+func (l *LUT) ycbcrTable() []uint16 {
+
+	l.ycbcrOnce.Do(func() {
+		l.ycbcr = buildTable(func(c1, c2, c3 uint8) int {
+			return l.layout.Bin(color.YCbCr{Y: c1, Cb: c2, Cr: c3}.RGBA())
+		})
+	})
+
+	return l.ycbcr
+
+}
+
+// buildTable returns a table with the bin of every combination of three 8-bit
+// components, indexed by c1<<16 | c2<<8 | c3. The table is filled by up to
+// NumCPU goroutines, each one computing a range of values of c1.
+func buildTable(bin func(c1, c2, c3 uint8) int) []uint16 {
+
+	table := make([]uint16, 1<<24)
+	workers := runtime.NumCPU()
+	if workers > 256 {
+		workers = 256
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+
+		wg.Add(1)
+		go func(first, last int) {
+
+			defer wg.Done()
+			for c1 := first; c1 < last; c1++ {
+				for c2 := 0; c2 < 256; c2++ {
+					for c3 := 0; c3 < 256; c3++ {
+						table[c1<<16|c2<<8|c3] = uint16(bin(uint8(c1), uint8(c2), uint8(c3)))
+					}
+				}
+			}
+
+		}(256*w/workers, 256*(w+1)/workers)
+
+	}
+
+	wg.Wait()
+	return table
+
+}
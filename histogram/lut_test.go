@@ -0,0 +1,167 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// newTestLUT returns a LUT for the given layout, failing the test if it can't be created.
+func newTestLUT(t testing.TB, layout Layout) *LUT {
+
+	lut, err := NewLUT(layout)
+	if err != nil {
+		t.Fatalf("NewLUT(%+v) error = %v", layout, err)
+	}
+
+	return lut
+
+}
+
+func TestNewLUT(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		layout Layout
+		valid  bool
+	}{
+		{"32 bins", Config32Bins, true},
+		{"LCh", ConfigLCh64Bins, true},
+		{"Invalid", Config{HueLevels: 0, SaturationLevels: 4, ValueLevels: 1}, false},
+		{"Nil", nil, false},
+		{"Too many bins", LabConfig{LightnessLevels: 64, ALevels: 64, BLevels: 32}, false},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := NewLUT(tt.layout)
+			if (got != nil) != tt.valid || (err == nil) != tt.valid {
+				t.Errorf("NewLUT(%+v) = %v, %v, want valid: %v", tt.layout, got, err, tt.valid)
+			}
+
+			if !tt.valid && !errors.Is(err, ErrInvalidLayout) {
+				t.Errorf("NewLUT(%+v) error = %v, want %v", tt.layout, err, ErrInvalidLayout)
+			}
+
+		})
+
+	}
+
+}
+
+func TestLUT_New(t *testing.T) {
+
+	lut := newTestLUT(t, Config64Bins)
+
+	// Translucent and 16-bit pixels can't be looked up in the tables.
+	translucent := image.NewNRGBA64(image.Rect(0, 0, 3, 1))
+	translucent.Set(0, 0, color.NRGBA64{R: 0xffff, A: 0x8000})
+	translucent.Set(1, 0, color.NRGBA64{R: 0x1234, G: 0x5678, B: 0x9abc, A: 0xffff})
+	translucent.Set(2, 0, color.NRGBA64{R: 0xffff, G: 0xffff, A: 0xffff})
+
+	images := imagesOfEveryType(lobsterCrop())
+	images["Generic"] = genericImage{images["RGBA"]}
+	images["Translucent"] = translucent
+
+	for name, img := range images {
+
+		t.Run(name, func(t *testing.T) {
+
+			got := must(lut.New(img, RoundClosest))
+			want := must(New(img, Config64Bins, RoundClosest))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LUT.New()\nGot: %v\nWanted: %v", got.Counts(), want.Counts())
+			}
+
+			got = must(lut.NewConcurrent(img, RoundClosest))
+			want = must(NewConcurrent(img, Config64Bins, RoundClosest))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LUT.NewConcurrent()\nGot: %v\nWanted: %v", got.Counts(), want.Counts())
+			}
+
+		})
+
+	}
+
+}
+
+func TestLUT_ForRegion(t *testing.T) {
+
+	lut := newTestLUT(t, Config32Bins)
+	img := getImageByRelativePath(`../pictures/lobster_medium.jpg`)
+
+	for _, rect := range []image.Rectangle{image.Rect(100, 200, 357, 391), image.Rect(-5, -5, 40, 30)} {
+
+		got := must(lut.ForRegion(img, rect, RoundClosest))
+		want := must(ForRegion(img, rect, Config32Bins, RoundClosest))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("LUT.ForRegion(%v)\nGot: %v\nWanted: %v", rect, got.Counts(), want.Counts())
+		}
+
+	}
+
+}
+
+func TestLUTSharedAcrossGoroutines(t *testing.T) {
+
+	lut := newTestLUT(t, Config32Bins)
+	img := lobsterCrop()
+	want := must(New(img, Config32Bins, RoundClosest)).Percentages()
+
+	var wg sync.WaitGroup
+	results := make([][]float64, 4)
+	for i := range results {
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = must(lut.New(img, RoundClosest)).Percentages()
+		}(i)
+
+	}
+
+	wg.Wait()
+	for i, got := range results {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("goroutine %d got %v, want %v", i, got, want)
+		}
+	}
+
+}
+
+func BenchmarkLUT(b *testing.B) {
+
+	src := getImageByRelativePath(`../pictures/beach_medium.jpg`)
+	images := imagesOfEveryType(src)
+	lut := newTestLUT(b, Config32Bins)
+
+	for _, name := range []string{"YCbCr", "RGBA"} {
+
+		img := images[name]
+
+		// Build the tables before timing.
+		lut.New(img, RoundClosest)
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				lut.New(img, RoundClosest)
+			}
+		})
+
+		b.Run(name+" without LUT", func(b *testing.B) {
+			benchmarkNew(b, img)
+		})
+
+	}
+
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+)
+
+// Mask decides which pixels of an image are counted in a histogram, returning
+// the fraction of the pixel at (x, y) that's counted, in [0, 1]. Like with
+// an AlphaPolicy, the pixels a Mask returns 0 for are excluded both from the
+// bins and from the total amount of pixels the percentages are computed from.
+// A Mask is a WeightFunc that's limited to [0, 1]. A nil Mask counts every pixel.
+type Mask func(x, y int) float64
+
+// ImageMask returns a Mask with the semantics of image/draw's masks: every
+// pixel of the image is counted with the alpha of the mask at the same point,
+// so fully transparent points exclude their pixel, opaque points count it
+// once and translucent ones count a fraction of it. Pixels outside of the
+// bounds of the mask are excluded. A nil mask counts every pixel.
+func ImageMask(mask image.Image) Mask {
+
+	if mask == nil {
+		return nil
+	}
+
+	// Alpha masks, such as the ones built by segmentation, are read directly.
+	if alpha, ok := mask.(*image.Alpha); ok {
+
+		return func(x, y int) float64 {
+
+			if !(image.Point{X: x, Y: y}).In(alpha.Rect) {
+				return 0
+			}
+
+			return float64(alpha.Pix[alpha.PixOffset(x, y)]) / 0xff
+
+		}
+
+	}
+
+	return func(x, y int) float64 {
+
+		if !(image.Point{X: x, Y: y}).In(mask.Bounds()) {
+			return 0
+		}
+
+		_, _, _, a := mask.At(x, y).RGBA()
+		return float64(a) / 0xffff
+
+	}
+
+}
+
+// PredicateMask returns a Mask that counts once every pixel
+// for which include returns true, and excludes the others.
+func PredicateMask(include func(x, y int) bool) Mask {
+
+	return func(x, y int) float64 {
+
+		if !include(x, y) {
+			return 0
+		}
+
+		return 1
+
+	}
+
+}
+
+// NewMasked returns the color Histogram of the pixels of the input image
+// selected by mask, using the given bin layout.
+func NewMasked(img image.Image, mask Mask, layout Layout, rounder Rounder) (Histogram, error) {
+	return NewWeighted(img, WeightFunc(mask), layout, rounder)
+}
+
+// NewMaskedWithAlpha returns the color Histogram of the pixels of the input
+// image selected by mask, like NewMasked does, weighting every pixel as
+// described by the alpha policy as well.
+func NewMaskedWithAlpha(img image.Image, mask Mask, layout Layout, rounder Rounder, alpha AlphaPolicy) (Histogram, error) {
+	return NewWeightedWithAlpha(img, WeightFunc(mask), layout, rounder, alpha)
+}
+
+// NewMaskedConcurrent returns the color Histogram of the pixels of the input
+// image selected by mask, like NewMasked does. This concurrent version splits
+// the image into up to NumCPU sub-images, using one goroutine per sub-image.
+func NewMaskedConcurrent(img image.Image, mask Mask, layout Layout, rounder Rounder) (Histogram, error) {
+	return NewMaskedContext(context.Background(), img, mask, layout, rounder, Options{})
+}
+
+// NewMaskedContext returns the color Histogram of the pixels of the input
+// image selected by mask, like NewMasked does, using the goroutines, tiling
+// and alpha policy set in opts. The goroutines stop as soon as ctx is done,
+// in which case an empty Histogram and ctx.Err() are returned.
+func NewMaskedContext(ctx context.Context, img image.Image, mask Mask, layout Layout, rounder Rounder, opts Options) (Histogram, error) {
+	return NewWeightedContext(ctx, img, WeightFunc(mask), layout, rounder, opts)
+}
@@ -0,0 +1,187 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// foregroundMask returns an alpha mask with the given bounds,
+// which is opaque inside of fg and transparent everywhere else.
+func foregroundMask(bounds, fg image.Rectangle) *image.Alpha {
+
+	mask := image.NewAlpha(bounds)
+	for y := fg.Min.Y; y < fg.Max.Y; y++ {
+		for x := fg.Min.X; x < fg.Max.X; x++ {
+			mask.SetAlpha(x, y, color.Alpha{A: 0xff})
+		}
+	}
+
+	return mask
+
+}
+
+func TestNewMasked(t *testing.T) {
+
+	img := lobsterCrop()
+	bounds := img.Bounds()
+	fg := image.Rect(bounds.Min.X+20, bounds.Min.Y+30, bounds.Max.X-40, bounds.Max.Y-10)
+	alpha := foregroundMask(bounds, fg)
+
+	masks := map[string]Mask{
+		"Alpha image":   ImageMask(alpha),
+		"Generic image": ImageMask(genericImage{alpha}),
+		"Predicate": PredicateMask(func(x, y int) bool {
+			return image.Pt(x, y).In(fg)
+		}),
+	}
+
+	lut := newTestLUT(t, Config32Bins)
+	want := must(ForRegion(img, fg, Config32Bins, RoundClosest))
+
+	for name, mask := range masks {
+
+		t.Run(name, func(t *testing.T) {
+
+			opts := Options{Workers: 3, Tiling: Tiling{Size: image.Pt(50, 40)}}
+			engine := newTestEngine(t, Config32Bins, opts)
+			defer engine.Close()
+
+			lutEngine := lut.NewEngine(opts)
+			defer lutEngine.Close()
+
+			builders := map[string]func() (Histogram, error){
+				"NewMasked": func() (Histogram, error) {
+					return NewMasked(img, mask, Config32Bins, RoundClosest)
+				},
+				"NewMaskedConcurrent": func() (Histogram, error) {
+					return NewMaskedConcurrent(img, mask, Config32Bins, RoundClosest)
+				},
+				"NewMaskedContext": func() (Histogram, error) {
+					return NewMaskedContext(context.Background(), img, mask, Config32Bins, RoundClosest, opts)
+				},
+				"Engine.NewMasked": func() (Histogram, error) {
+					return engine.NewMasked(img, mask, RoundClosest)
+				},
+				"LUT.NewMasked": func() (Histogram, error) {
+					return lut.NewMasked(img, mask, RoundClosest)
+				},
+				"LUT.NewMaskedConcurrent": func() (Histogram, error) {
+					return lut.NewMaskedConcurrent(img, mask, RoundClosest)
+				},
+				"LUT Engine.NewMasked": func() (Histogram, error) {
+					return lutEngine.NewMasked(img, mask, RoundClosest)
+				},
+			}
+
+			for builderName, build := range builders {
+				if got, err := build(); err != nil || !reflect.DeepEqual(got, want) {
+					t.Errorf("%s() = %v, %v, want %v", builderName, got.Counts(), err, want.Counts())
+				}
+			}
+
+		})
+
+	}
+
+}
+
+func TestImageMaskSemantics(t *testing.T) {
+
+	img := productImage()
+	blue := Config32Bins.quantize(240, 100, 100)
+
+	// Half of the blue square is fully selected and half is
+	// selected by half, the rest is outside of the mask's bounds.
+	mask := image.NewAlpha(image.Rect(3, 3, 7, 7))
+	for x := 3; x < 7; x++ {
+		for y := 3; y < 7; y++ {
+			if x < 5 {
+				mask.SetAlpha(x, y, color.Alpha{A: 0xff})
+			} else {
+				mask.SetAlpha(x, y, color.Alpha{A: 0x80})
+			}
+		}
+	}
+
+	want := 8 + 8*128.0/255
+	for _, m := range []Mask{ImageMask(mask), ImageMask(genericImage{mask})} {
+
+		got := must(NewMasked(img, m, Config32Bins, RoundNone))
+		if !closeTo(got.Pixels(), want) || !closeTo(got.Counts()[blue], want) {
+			t.Errorf("NewMasked() pixels = %v, blue = %v, want %v", got.Pixels(), got.Counts()[blue], want)
+		}
+
+	}
+
+	// The mask and the alpha policy are combined.
+	half := ImageMask(image.NewUniform(color.Alpha{A: 0x80}))
+	lut := newTestLUT(t, Config32Bins)
+	combined := map[string]Histogram{
+		"NewMaskedContext":       must(NewMaskedContext(context.Background(), img, half, Config32Bins, RoundNone, Options{Alpha: SkipTransparent})),
+		"NewMaskedWithAlpha":     must(NewMaskedWithAlpha(img, half, Config32Bins, RoundNone, SkipTransparent)),
+		"LUT.NewMaskedWithAlpha": must(lut.NewMaskedWithAlpha(img, half, RoundNone, SkipTransparent)),
+	}
+
+	for name, got := range combined {
+		if want := 26 * 128.0 / 255; !closeTo(got.Pixels(), want) {
+			t.Errorf("%s() with an alpha policy pixels = %v, want %v", name, got.Pixels(), want)
+		}
+	}
+
+}
+
+func TestNewMaskedNilMask(t *testing.T) {
+
+	img := lobsterCrop()
+	want := must(New(img, Config64Bins, RoundClosest))
+
+	for _, mask := range []Mask{nil, ImageMask(nil)} {
+
+		if got, err := NewMasked(img, mask, Config64Bins, RoundClosest); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("NewMasked() with a nil mask = %v, %v, want %v", got.Counts(), err, want.Counts())
+		}
+
+		if got, err := NewMaskedConcurrent(img, mask, Config64Bins, RoundClosest); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("NewMaskedConcurrent() with a nil mask = %v, %v, want %v", got.Counts(), err, want.Counts())
+		}
+
+	}
+
+}
+
+func TestNewMaskedErrors(t *testing.T) {
+
+	img := lobsterCrop()
+	empty := image.NewAlpha(img.Bounds())
+	outside := foregroundMask(img.Bounds().Add(img.Bounds().Size()), img.Bounds().Add(img.Bounds().Size()))
+
+	for _, mask := range []Mask{ImageMask(empty), ImageMask(outside), PredicateMask(func(x, y int) bool { return false })} {
+
+		if _, err := NewMasked(img, mask, Config32Bins, RoundClosest); err != ErrEmptyImage {
+			t.Errorf("NewMasked() selecting no pixel error = %v, want %v", err, ErrEmptyImage)
+		}
+
+		if _, err := NewMaskedConcurrent(img, mask, Config32Bins, RoundClosest); err != ErrEmptyImage {
+			t.Errorf("NewMaskedConcurrent() selecting no pixel error = %v, want %v", err, ErrEmptyImage)
+		}
+
+	}
+
+	if _, err := NewMasked(img, nil, nil, RoundClosest); err != ErrInvalidLayout {
+		t.Errorf("NewMasked() with a nil layout error = %v, want %v", err, ErrInvalidLayout)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewMaskedContext(ctx, img, nil, Config32Bins, RoundClosest, Options{}); err != context.Canceled {
+		t.Errorf("NewMaskedContext() with a canceled context error = %v, want %v", err, context.Canceled)
+	}
+
+}
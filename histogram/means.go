@@ -0,0 +1,88 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+)
+
+// NewWithMeans returns the color Histogram of the input image, like NewContext
+// does, together with the mean color of the pixels counted in each of its
+// bins, which is more representative of the bin than the center of its
+// levels. The colors are averaged with the same weights the pixels are
+// counted with, after removing the alpha premultiplication, and are opaque.
+// The bins that are empty have a transparent black mean color.
+// Since every pixel has to belong to a single bin, opts.Kernel is ignored.
+func NewWithMeans(ctx context.Context, img image.Image, layout Layout, rounder Rounder, opts Options) (Histogram, []color.RGBA64, error) {
+
+	if err := validate(layout, rounder); err != nil {
+		return Histogram{}, nil, err
+	}
+
+	// The bins are followed by the sums of the R, G and B components of their pixels.
+	bins := layout.Bins()
+	sums, pixels, err := countConcurrent(ctx, img, img.Bounds(), nil, opts, 4*bins, meansCounter(layout.Bin, bins, opts.Alpha))
+	if err != nil {
+		return Histogram{}, nil, err
+	}
+
+	histogram, err := newCountedHistogram(layout, rounder, pixels, sums[:bins:bins])
+	if err != nil {
+		return Histogram{}, nil, err
+	}
+
+	means := make([]color.RGBA64, bins)
+	for i, count := range histogram.counts {
+
+		if count <= 0 {
+			continue
+		}
+
+		components := sums[bins+3*i : bins+3*i+3]
+		means[i] = color.RGBA64{
+			R: uint16(math.Round(components[0] / count)),
+			G: uint16(math.Round(components[1] / count)),
+			B: uint16(math.Round(components[2] / count)),
+			A: 0xffff,
+		}
+
+	}
+
+	return histogram, means, nil
+
+}
+
+// meansCounter returns a counter that maps every pixel to its bin with bin,
+// weighting it as described by the alpha policy, and adds its weighted,
+// non-premultiplied R, G and B components to the sums of the bin, which
+// follow the amount of bins of the layout.
+func meansCounter(bin func(r, g, b, a uint32) int, bins int, alpha AlphaPolicy) counter {
+
+	return func(img image.Image, rect image.Rectangle, weights WeightFunc, counts []float64) float64 {
+
+		return eachWeightedPixel(img, rect, alpha, weights, func(r, g, b, a uint32, weight float64) {
+
+			index := bin(r, g, b, a)
+			counts[index] += weight
+
+			// Fully transparent pixels are black, like for RGBAToHSV.
+			if a == 0 {
+				return
+			}
+
+			scale := weight * 0xffff / float64(a)
+			sums := counts[bins+3*index : bins+3*index+3]
+			sums[0] += scale * float64(r)
+			sums[1] += scale * float64(g)
+			sums[2] += scale * float64(b)
+
+		})
+
+	}
+
+}
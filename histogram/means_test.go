@@ -0,0 +1,118 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestNewWithMeans(t *testing.T) {
+
+	// Two blues in the same bin, a half transparent red and a transparent pixel.
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.Set(0, 0, color.NRGBA{R: 10, G: 20, B: 200, A: 255})
+	img.Set(1, 0, color.NRGBA{R: 30, G: 40, B: 220, A: 255})
+	img.Set(2, 0, color.NRGBA{R: 250, A: 128})
+
+	blue := Config32Bins.Bin(color.NRGBA{R: 10, G: 20, B: 200, A: 255}.RGBA())
+	red := Config32Bins.Bin(color.NRGBA{R: 250, A: 128}.RGBA())
+	black := Config32Bins.Bin(0, 0, 0, 0)
+
+	tests := []struct {
+		name  string
+		alpha AlphaPolicy
+		means map[int]color.RGBA64
+	}{
+		{"Count every pixel", nil, map[int]color.RGBA64{
+			blue:  {R: 20 * 0x101, G: 30 * 0x101, B: 210 * 0x101, A: 0xffff},
+			red:   {R: 250 * 0x101, A: 0xffff},
+			black: {A: 0xffff},
+		}},
+		{"SkipTransparent", SkipTransparent, map[int]color.RGBA64{
+			blue: {R: 20 * 0x101, G: 30 * 0x101, B: 210 * 0x101, A: 0xffff},
+			red:  {R: 250 * 0x101, A: 0xffff},
+		}},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			opts := Options{Alpha: tt.alpha, Tiling: Tiling{Size: image.Pt(1, 1)}}
+			got, means, err := NewWithMeans(context.Background(), img, Config32Bins, RoundClosest, opts)
+			if err != nil {
+				t.Fatalf("NewWithMeans() error = %v", err)
+			}
+
+			if want := must(NewContext(context.Background(), img, Config32Bins, RoundClosest, opts)); !reflect.DeepEqual(got, want) {
+				t.Errorf("NewWithMeans() histogram = %v, want %v", got.Counts(), want.Counts())
+			}
+
+			for i, mean := range means {
+
+				want := tt.means[i]
+				if mean.A != want.A || !near16(mean.R, want.R) || !near16(mean.G, want.G) || !near16(mean.B, want.B) {
+					t.Errorf("mean of bin %d = %v, want %v", i, mean, want)
+				}
+
+			}
+
+		})
+
+	}
+
+}
+
+// near16 reports whether two 16-bit components only differ by rounding errors.
+func near16(a, b uint16) bool {
+	return int(a)-int(b) <= 0x101 && int(b)-int(a) <= 0x101
+}
+
+func TestNewWithMeansOfImage(t *testing.T) {
+
+	img := lobsterCrop()
+	got, means, err := NewWithMeans(context.Background(), img, Config64Bins, RoundNone, Options{})
+	if err != nil {
+		t.Fatalf("NewWithMeans() error = %v", err)
+	}
+
+	// The mean color of every populated bin should fall in the bin.
+	for i, count := range got.Counts() {
+
+		if count == 0 {
+			continue
+		}
+
+		if bin := Config64Bins.Bin(means[i].RGBA()); bin != i && count > 100 {
+			t.Errorf("mean color %v of bin %d, with %v pixels, is in bin %d", means[i], i, count, bin)
+		}
+
+	}
+
+}
+
+func TestNewWithMeansErrors(t *testing.T) {
+
+	img := lobsterCrop()
+	if _, _, err := NewWithMeans(context.Background(), img, nil, RoundClosest, Options{}); err != ErrInvalidLayout {
+		t.Errorf("NewWithMeans() with a nil layout error = %v, want %v", err, ErrInvalidLayout)
+	}
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	if _, _, err := NewWithMeans(context.Background(), transparent, Config32Bins, RoundClosest, Options{Alpha: SkipTransparent}); err != ErrEmptyImage {
+		t.Errorf("NewWithMeans() of a transparent image error = %v, want %v", err, ErrEmptyImage)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := NewWithMeans(ctx, img, Config32Bins, RoundClosest, Options{}); err != context.Canceled {
+		t.Errorf("NewWithMeans() with a canceled context error = %v, want %v", err, context.Canceled)
+	}
+
+}
@@ -0,0 +1,43 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"runtime"
+)
+
+// Options controls how concurrent histograms are computed.
+// The zero value uses up to NumCPU goroutines and the default Tiling,
+// counting every pixel once in a single bin, like the functions that
+// take no Options.
+type Options struct {
+
+	// Workers is the maximum amount of goroutines used to compute
+	// a histogram. If it's not positive, NumCPU is used.
+	Workers int
+
+	// Tiling describes how images are divided among the workers.
+	Tiling Tiling
+
+	// Alpha decides how much each pixel counts, depending on its
+	// opacity. If it's nil, every pixel is counted once.
+	Alpha AlphaPolicy
+
+	// Kernel splits each pixel among the bins that are closest to its
+	// color, if the layout is a SoftLayout, as all the layouts of this
+	// package are. If it's nil, each pixel is mapped to a single bin.
+	Kernel Kernel
+}
+
+// workers returns the maximum amount of goroutines to use.
+func (o Options) workers() int {
+
+	if o.Workers > 0 {
+		return o.Workers
+	}
+
+	return runtime.NumCPU()
+
+}
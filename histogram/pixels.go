@@ -0,0 +1,144 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"image"
+	"image/color"
+)
+
+// eachPixel calls fn with the alpha-premultiplied RGBA components of every
+// pixel of img in rect, exactly as img.At(x, y).RGBA() would return them.
+// The most common concrete image types are read directly from their Pix
+// buffers, avoiding to allocate a color.Color for each pixel.
+// The pixels inside of the bounds of img are read row by row, from left to right.
+func eachPixel(img image.Image, rect image.Rectangle, fn func(r, g, b, a uint32)) {
+
+	inside := rect.Intersect(img.Bounds())
+	if !inside.Empty() {
+
+		switch img := img.(type) {
+		case *image.RGBA:
+			eachRGBAPixel(img, inside, fn)
+		case *image.NRGBA:
+			eachNRGBAPixel(img, inside, fn)
+		case *image.YCbCr:
+			eachYCbCrPixel(img, inside, fn)
+		case *image.Gray:
+			eachGrayPixel(img, inside, fn)
+		case *image.Paletted:
+			eachPalettedPixel(img, inside, fn)
+		default:
+			eachGenericPixel(img, inside, image.ZR, fn)
+		}
+
+	}
+
+	// Pixels outside of the image are read with At,
+	// which returns the zero color of the image.
+	if inside != rect {
+		eachGenericPixel(img, rect, inside, fn)
+	}
+
+}
+
+// eachGenericPixel reads every pixel of img in rect, skipping the ones in skip, with At.
+func eachGenericPixel(img image.Image, rect, skip image.Rectangle, fn func(r, g, b, a uint32)) {
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+
+			if (image.Point{X: x, Y: y}).In(skip) {
+				continue
+			}
+
+			fn(img.At(x, y).RGBA())
+
+		}
+
+	}
+
+}
+
+func eachRGBAPixel(img *image.RGBA, rect image.Rectangle, fn func(r, g, b, a uint32)) {
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+
+		pix := img.Pix[img.PixOffset(rect.Min.X, y):img.PixOffset(rect.Max.X, y)]
+		for i := 0; i < len(pix); i += 4 {
+			fn(color.RGBA{R: pix[i], G: pix[i+1], B: pix[i+2], A: pix[i+3]}.RGBA())
+		}
+
+	}
+
+}
+
+func eachNRGBAPixel(img *image.NRGBA, rect image.Rectangle, fn func(r, g, b, a uint32)) {
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+
+		pix := img.Pix[img.PixOffset(rect.Min.X, y):img.PixOffset(rect.Max.X, y)]
+		for i := 0; i < len(pix); i += 4 {
+			fn(color.NRGBA{R: pix[i], G: pix[i+1], B: pix[i+2], A: pix[i+3]}.RGBA())
+		}
+
+	}
+
+}
+
+func eachYCbCrPixel(img *image.YCbCr, rect image.Rectangle, fn func(r, g, b, a uint32)) {
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+
+			yi, ci := img.YOffset(x, y), img.COffset(x, y)
+			fn(color.YCbCr{Y: img.Y[yi], Cb: img.Cb[ci], Cr: img.Cr[ci]}.RGBA())
+
+		}
+
+	}
+
+}
+
+func eachGrayPixel(img *image.Gray, rect image.Rectangle, fn func(r, g, b, a uint32)) {
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+
+		pix := img.Pix[img.PixOffset(rect.Min.X, y):img.PixOffset(rect.Max.X, y)]
+		for _, gray := range pix {
+			fn(color.Gray{Y: gray}.RGBA())
+		}
+
+	}
+
+}
+
+func eachPalettedPixel(img *image.Paletted, rect image.Rectangle, fn func(r, g, b, a uint32)) {
+
+	// An empty palette makes At return nil, let it fail the usual way.
+	if len(img.Palette) == 0 {
+		eachGenericPixel(img, rect, image.ZR, fn)
+		return
+	}
+
+	// Convert each color of the palette only once.
+	palette := make([][4]uint32, len(img.Palette))
+	for i, c := range img.Palette {
+		palette[i][0], palette[i][1], palette[i][2], palette[i][3] = c.RGBA()
+	}
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+
+		pix := img.Pix[img.PixOffset(rect.Min.X, y):img.PixOffset(rect.Max.X, y)]
+		for _, index := range pix {
+			c := palette[index]
+			fn(c[0], c[1], c[2], c[3])
+		}
+
+	}
+
+}
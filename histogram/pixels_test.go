@@ -0,0 +1,175 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+// genericImage hides the concrete type of an image, forcing the use of At.
+type genericImage struct {
+	image.Image
+}
+
+// imagesOfEveryType returns the input image converted into each of the
+// concrete image types that have a fast path, keyed by their name.
+func imagesOfEveryType(src image.Image) map[string]image.Image {
+
+	bounds := src.Bounds()
+
+	rgba := image.NewRGBA(bounds)
+	draw.Draw(rgba, bounds, src, bounds.Min, draw.Src)
+
+	// Give the NRGBA image some transparency, to check premultiplication.
+	nrgba := image.NewNRGBA(bounds)
+	draw.Draw(nrgba, bounds, src, bounds.Min, draw.Src)
+	for i := 3; i < len(nrgba.Pix); i += 4 {
+		nrgba.Pix[i] = uint8(i / 4)
+	}
+
+	gray := image.NewGray(bounds)
+	draw.Draw(gray, bounds, src, bounds.Min, draw.Src)
+
+	paletted := image.NewPaletted(bounds, palette.Plan9)
+	draw.Draw(paletted, bounds, src, bounds.Min, draw.Src)
+
+	images := map[string]image.Image{
+		"RGBA":     rgba,
+		"NRGBA":    nrgba,
+		"Gray":     gray,
+		"Paletted": paletted,
+	}
+
+	if ycbcr, ok := src.(*image.YCbCr); ok {
+		images["YCbCr"] = ycbcr
+	}
+
+	return images
+
+}
+
+// collectPixels returns how many times each color is passed to fn by eachPixel.
+func collectPixels(img image.Image, rect image.Rectangle) map[[4]uint32]int {
+
+	colors := make(map[[4]uint32]int)
+	eachPixel(img, rect, func(r, g, b, a uint32) {
+		colors[[4]uint32{r, g, b, a}]++
+	})
+
+	return colors
+
+}
+
+// lobsterCrop returns a crop of lobster_medium.jpg, so that the images built
+// from it have a stride that's larger than their width and tests are quick.
+func lobsterCrop() image.Image {
+
+	src := getImageByRelativePath(`../pictures/lobster_medium.jpg`)
+	return src.(*image.YCbCr).SubImage(image.Rect(100, 200, 357, 391))
+
+}
+
+func TestEachPixel(t *testing.T) {
+
+	for name, img := range imagesOfEveryType(lobsterCrop()) {
+
+		bounds := img.Bounds()
+		rectangles := map[string]image.Rectangle{
+			"Bounds":       bounds,
+			"Inset":        bounds.Inset(13),
+			"Larger":       image.Rect(bounds.Min.X-3, bounds.Min.Y-2, bounds.Max.X+1, bounds.Max.Y+1),
+			"Outside":      bounds.Add(bounds.Size()),
+			"Inclusive":    image.Rectangle{Min: bounds.Min, Max: bounds.Max.Add(image.Pt(1, 1))},
+			"Single pixel": image.Rect(bounds.Min.X+5, bounds.Min.Y+7, bounds.Min.X+6, bounds.Min.Y+8),
+		}
+
+		for rectName, rect := range rectangles {
+
+			t.Run(name+" "+rectName, func(t *testing.T) {
+
+				got := collectPixels(img, rect)
+				want := collectPixels(genericImage{img}, rect)
+
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("eachPixel() read %d distinct colors, want %d", len(got), len(want))
+				}
+
+			})
+
+		}
+
+	}
+
+}
+
+func TestNewFastPaths(t *testing.T) {
+
+	for name, img := range imagesOfEveryType(lobsterCrop()) {
+
+		t.Run(name, func(t *testing.T) {
+
+			for _, layout := range []Layout{Config64Bins, ConfigLCh64Bins} {
+
+				got := must(New(img, layout, RoundNone)).Counts()
+				want := must(New(genericImage{img}, layout, RoundNone)).Counts()
+
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("New() %+v\nGot: %v\nWanted: %v", layout, got, want)
+				}
+
+			}
+
+		})
+
+	}
+
+}
+
+func TestEachPalettedPixelWithEmptyPalette(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("eachPixel() with an empty palette did not panic like At does")
+		}
+	}()
+
+	img := image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{})
+	eachPixel(img, img.Bounds(), func(r, g, b, a uint32) {})
+
+}
+
+func benchmarkNew(b *testing.B, img image.Image) {
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New(img, Config32Bins, RoundClosest)
+	}
+
+}
+
+func BenchmarkNewFastPaths(b *testing.B) {
+
+	src := getImageByRelativePath(`../pictures/beach_medium.jpg`)
+	images := imagesOfEveryType(src)
+
+	for _, name := range []string{"YCbCr", "RGBA", "NRGBA", "Gray", "Paletted"} {
+
+		img := images[name]
+		b.Run(name, func(b *testing.B) {
+			benchmarkNew(b, img)
+		})
+
+		b.Run(name+" with At", func(b *testing.B) {
+			benchmarkNew(b, genericImage{img})
+		})
+
+	}
+
+}
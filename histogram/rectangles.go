@@ -6,8 +6,99 @@ package histogram
 
 import (
 	"image"
+	"runtime"
 )
 
+// Tiling describes how an image is divided into tiles, so that they can be
+// processed concurrently. Tiles are half-open, like image.Rectangle, and
+// cover every pixel of the image exactly once.
+// The zero value splits the image in up to NumCPU vertical strips.
+type Tiling struct {
+
+	// Size is the size of the tiles. The tiles on the right and
+	// bottom edges of the image may be smaller. If either coordinate
+	// is not positive, Rows and Columns are used instead.
+	Size image.Point
+
+	// Rows and Columns divide the image into a grid of Rows*Columns tiles
+	// of equal size, give or take a pixel. If either one is not positive,
+	// the default tiling is used. Images with fewer pixels than rows or
+	// columns are divided into fewer tiles.
+	Rows, Columns int
+}
+
+// Tiles returns the tiles of the rectangle, row by row.
+// Empty tiles are never returned.
+func (t Tiling) Tiles(rectangle image.Rectangle) []image.Rectangle {
+
+	switch {
+	case t.Size.X > 0 && t.Size.Y > 0:
+		return tilesOfSize(rectangle, t.Size)
+	case t.Rows > 0 && t.Columns > 0:
+		return nonEmpty(grid(rectangle, t.Rows, t.Columns))
+	default:
+		return nonEmpty(splitInto(runtime.NumCPU(), rectangle))
+	}
+
+}
+
+// tilesOfSize splits a rectangle into tiles of the given size, row by row.
+func tilesOfSize(rectangle image.Rectangle, size image.Point) []image.Rectangle {
+
+	var tiles []image.Rectangle
+	for y := rectangle.Min.Y; y < rectangle.Max.Y; y += size.Y {
+
+		for x := rectangle.Min.X; x < rectangle.Max.X; x += size.X {
+			tile := image.Rect(x, y, x+size.X, y+size.Y)
+			tiles = append(tiles, tile.Intersect(rectangle))
+		}
+
+	}
+
+	return tiles
+
+}
+
+// grid splits a rectangle into rows*columns tiles, row by row.
+// The edges of the tiles are spread as evenly as possible, so some
+// tiles are empty if the rectangle has fewer rows or columns than them.
+func grid(rectangle image.Rectangle, rows, columns int) []image.Rectangle {
+
+	width, height := rectangle.Dx(), rectangle.Dy()
+	tiles := make([]image.Rectangle, 0, rows*columns)
+	for row := 0; row < rows; row++ {
+
+		minY := rectangle.Min.Y + row*height/rows
+		maxY := rectangle.Min.Y + (row+1)*height/rows
+
+		for column := 0; column < columns; column++ {
+
+			minX := rectangle.Min.X + column*width/columns
+			maxX := rectangle.Min.X + (column+1)*width/columns
+			tiles = append(tiles, image.Rect(minX, minY, maxX, maxY))
+
+		}
+
+	}
+
+	return tiles
+
+}
+
+// nonEmpty removes the empty rectangles from the input slice, in place.
+func nonEmpty(rectangles []image.Rectangle) []image.Rectangle {
+
+	filtered := rectangles[:0]
+	for _, r := range rectangles {
+		if !r.Empty() {
+			filtered = append(filtered, r)
+		}
+	}
+
+	return filtered
+
+}
+
 // splitInto splits a rectangle in up to amount parts.
 func splitInto(amount int, rectangle image.Rectangle) []image.Rectangle {
 
@@ -29,6 +120,7 @@ func splitInto(amount int, rectangle image.Rectangle) []image.Rectangle {
 }
 
 // split splits a Rectangle in two, horizontally.
+// Like the input, the two halves don't include their Max point.
 func split(r image.Rectangle) []image.Rectangle {
 
 	return []image.Rectangle{
@@ -39,7 +131,7 @@ func split(r image.Rectangle) []image.Rectangle {
 				Y: r.Min.Y,
 			},
 			Max: image.Point{
-				X: ((r.Max.X + r.Min.X) / 2) - 1,
+				X: (r.Max.X + r.Min.X) / 2,
 				Y: r.Max.Y,
 			},
 		},
@@ -4,6 +4,7 @@ import (
 	"image"
 	"reflect"
 	"testing"
+	"testing/quick"
 )
 
 func Test_split(t *testing.T) {
@@ -18,22 +19,22 @@ func Test_split(t *testing.T) {
 		{
 			name: "1000x1000",
 			args: args{r: image.Rect(0, 0, 1000, 1000)},
-			want: []image.Rectangle{image.Rect(0, 0, 499, 1000), image.Rect(500, 0, 1000, 1000)},
+			want: []image.Rectangle{image.Rect(0, 0, 500, 1000), image.Rect(500, 0, 1000, 1000)},
 		},
 		{
 			name: "333x333",
 			args: args{r: image.Rect(0, 0, 333, 333)},
-			want: []image.Rectangle{image.Rect(0, 0, 165, 333), image.Rect(166, 0, 333, 333)},
+			want: []image.Rectangle{image.Rect(0, 0, 166, 333), image.Rect(166, 0, 333, 333)},
 		},
 		{
 			name: "500x500 no 0,0",
 			args: args{r: image.Rect(1, 1, 500, 500)},
-			want: []image.Rectangle{image.Rect(1, 1, 249, 500), image.Rect(250, 1, 500, 500)},
+			want: []image.Rectangle{image.Rect(1, 1, 250, 500), image.Rect(250, 1, 500, 500)},
 		},
 		{
 			name: "747x915 no 0,0",
 			args: args{r: image.Rect(1, 1, 747, 915)},
-			want: []image.Rectangle{image.Rect(1, 1, 373, 915), image.Rect(374, 1, 747, 915)},
+			want: []image.Rectangle{image.Rect(1, 1, 374, 915), image.Rect(374, 1, 747, 915)},
 		},
 	}
 	for _, tt := range tests {
@@ -59,9 +60,9 @@ func Test_splitInto(t *testing.T) {
 			name: "4 rectangles from 1000x1000",
 			args: args{amount: 4, rectangle: image.Rect(0, 0, 1000, 1000)},
 			want: []image.Rectangle{
-				image.Rect(0, 0, 248, 1000),
-				image.Rect(249, 0, 499, 1000),
-				image.Rect(500, 0, 749, 1000),
+				image.Rect(0, 0, 250, 1000),
+				image.Rect(250, 0, 500, 1000),
+				image.Rect(500, 0, 750, 1000),
 				image.Rect(750, 0, 1000, 1000),
 			},
 		},
@@ -69,13 +70,13 @@ func Test_splitInto(t *testing.T) {
 			name: "8 rectangles from 1000x1000",
 			args: args{amount: 8, rectangle: image.Rect(0, 0, 1000, 1000)},
 			want: []image.Rectangle{
-				image.Rect(0, 0, 123, 1000),
-				image.Rect(124, 0, 248, 1000),
-				image.Rect(249, 0, 373, 1000),
-				image.Rect(374, 0, 499, 1000),
-				image.Rect(500, 0, 623, 1000),
-				image.Rect(624, 0, 749, 1000),
-				image.Rect(750, 0, 874, 1000),
+				image.Rect(0, 0, 125, 1000),
+				image.Rect(125, 0, 250, 1000),
+				image.Rect(250, 0, 375, 1000),
+				image.Rect(375, 0, 500, 1000),
+				image.Rect(500, 0, 625, 1000),
+				image.Rect(625, 0, 750, 1000),
+				image.Rect(750, 0, 875, 1000),
 				image.Rect(875, 0, 1000, 1000),
 			},
 		},
@@ -88,3 +89,106 @@ func Test_splitInto(t *testing.T) {
 		})
 	}
 }
+
+// tilings returns the tilings used by the property tests, built from arbitrary values.
+func tilings(sizeX, sizeY, rows, columns uint8) map[string]Tiling {
+
+	return map[string]Tiling{
+		"Default": {},
+		"Size":    {Size: image.Pt(int(sizeX%64)+1, int(sizeY%64)+1)},
+		"Grid":    {Rows: int(rows%32) + 1, Columns: int(columns%32) + 1},
+	}
+
+}
+
+func TestTiling_Tiles(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		tiling Tiling
+		rect   image.Rectangle
+		want   []image.Rectangle
+	}{
+		{
+			name:   "Size",
+			tiling: Tiling{Size: image.Pt(4, 3)},
+			rect:   image.Rect(-2, 1, 7, 5),
+			want: []image.Rectangle{
+				image.Rect(-2, 1, 2, 4), image.Rect(2, 1, 6, 4), image.Rect(6, 1, 7, 4),
+				image.Rect(-2, 4, 2, 5), image.Rect(2, 4, 6, 5), image.Rect(6, 4, 7, 5),
+			},
+		},
+		{
+			name:   "Grid",
+			tiling: Tiling{Rows: 2, Columns: 3},
+			rect:   image.Rect(10, 10, 20, 15),
+			want: []image.Rectangle{
+				image.Rect(10, 10, 13, 12), image.Rect(13, 10, 16, 12), image.Rect(16, 10, 20, 12),
+				image.Rect(10, 12, 13, 15), image.Rect(13, 12, 16, 15), image.Rect(16, 12, 20, 15),
+			},
+		},
+		{
+			name:   "Grid larger than the rectangle",
+			tiling: Tiling{Rows: 1, Columns: 4},
+			rect:   image.Rect(0, 0, 2, 1),
+			want:   []image.Rectangle{image.Rect(0, 0, 1, 1), image.Rect(1, 0, 2, 1)},
+		},
+		{
+			name:   "Empty rectangle",
+			tiling: Tiling{},
+			rect:   image.Rect(5, 5, 5, 10),
+			want:   []image.Rectangle{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tiling.Tiles(tt.rect); len(got)+len(tt.want) > 0 && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+}
+
+func TestTilingCoversEveryPixelOnce(t *testing.T) {
+
+	property := func(minX, minY int16, width, height, sizeX, sizeY, rows, columns uint8) bool {
+
+		rect := image.Rect(int(minX), int(minY), int(minX)+int(width), int(minY)+int(height))
+		for name, tiling := range tilings(sizeX, sizeY, rows, columns) {
+
+			covered := make([]int, rect.Dx()*rect.Dy())
+			for _, tile := range tiling.Tiles(rect) {
+
+				if tile.Empty() || !tile.In(rect) {
+					t.Logf("%s tiling of %v: tile %v is empty or out of bounds", name, rect, tile)
+					return false
+				}
+
+				for y := tile.Min.Y; y < tile.Max.Y; y++ {
+					for x := tile.Min.X; x < tile.Max.X; x++ {
+						covered[(y-rect.Min.Y)*rect.Dx()+x-rect.Min.X]++
+					}
+				}
+
+			}
+
+			for i, times := range covered {
+				if times != 1 {
+					t.Logf("%s tiling of %v: pixel %d covered %d times", name, rect, i, times)
+					return false
+				}
+			}
+
+		}
+
+		return true
+
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+
+}
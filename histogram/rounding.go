@@ -0,0 +1,258 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Rounder turns the pixel counts of a histogram into rounded percentages.
+// Both the RoundingMode constants and RoundFunc implement Rounder, so any
+// Rounder can be passed to the functions that compute a Histogram, and any
+// Histogram can be rounded with a different Rounder with its Round method.
+type Rounder interface {
+
+	// Round returns the rounded percentages of pixels mapped to each bin,
+	// given the amount of pixels in each bin of the layout and their total.
+	// The input counts must not be modified.
+	Round(layout Layout, pixels float64, counts []float64) []float64
+}
+
+// RoundingMode is the way the percentages of a Histogram are rounded.
+type RoundingMode int
+
+// The rounding modes implement Rounder, so they can be
+// passed to every function that computes a Histogram.
+const (
+	// RoundClosest will round to the closest value using math.Round
+	RoundClosest RoundingMode = iota
+
+	// RoundUp will round to the closest bigger value using math.Ceil
+	RoundUp
+
+	// RoundDown will round to the closest lower value using math.Trunc
+	RoundDown
+
+	// RoundNone will not round, keeping the exact percentages
+	RoundNone
+
+	// RoundLargestRemainder will round down and then give the remaining
+	// percentage points to the bins with the largest remainders, so that
+	// the percentages always sum to exactly 100
+	RoundLargestRemainder
+)
+
+// Valid reports whether m is one of the known rounding modes.
+func (m RoundingMode) Valid() bool {
+	return m >= RoundClosest && m <= RoundLargestRemainder
+}
+
+// String returns the name of the rounding mode.
+func (m RoundingMode) String() string {
+
+	switch m {
+	case RoundClosest:
+		return "RoundClosest"
+	case RoundUp:
+		return "RoundUp"
+	case RoundDown:
+		return "RoundDown"
+	case RoundNone:
+		return "RoundNone"
+	case RoundLargestRemainder:
+		return "RoundLargestRemainder"
+	default:
+		return "RoundingMode(" + strconv.Itoa(int(m)) + ")"
+	}
+
+}
+
+// RoundFunc is a Rounder that rounds percentages with a function, such as
+// math.Round. Like with RoundClosest, RoundUp and RoundDown, bins of HSV
+// layouts that only differ by their Value level are rounded cumulatively.
+type RoundFunc func(x float64) float64
+
+// RoundHalfEven rounds percentages to the closest integer, rounding
+// halves to the even one, which is also known as banker's rounding.
+var RoundHalfEven Rounder = RoundFunc(math.RoundToEven)
+
+// Decimals returns a Rounder that rounds percentages to the closest
+// value with the given amount of decimal places.
+func Decimals(places int) Rounder {
+
+	scale := math.Pow10(places)
+	return RoundFunc(func(x float64) float64 {
+		return math.Round(x*scale) / scale
+	})
+
+}
+
+// Stochastic returns a Rounder that rounds percentages up with a probability
+// equal to their fractional part, and down otherwise, so that the expected
+// value of each rounded percentage is the exact one. Unlike RoundFunc, it
+// rounds every bin on its own, since two cumulative sums rounded at random
+// in opposite directions would make the bin between them negative.
+// The random numbers are generated from the given seed, making the results
+// reproducible if the Rounder is used by one goroutine at a time.
+// The Rounder is safe for concurrent use by multiple goroutines.
+func Stochastic(seed int64) Rounder {
+	return &stochastic{random: rand.New(rand.NewSource(seed))}
+}
+
+// stochastic is the Rounder returned by Stochastic.
+type stochastic struct {
+	mutex  sync.Mutex
+	random *rand.Rand
+}
+
+// Round returns the percentages of the input counts, each one
+// rounded up with a probability equal to its fractional part.
+func (s *stochastic) Round(layout Layout, pixels float64, counts []float64) []float64 {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	percentages := make([]float64, len(counts))
+	for i, count := range counts {
+
+		exact := count * 100 / pixels
+		percentages[i] = math.Floor(exact)
+		if s.random.Float64() < exact-percentages[i] {
+			percentages[i]++
+		}
+
+	}
+
+	return percentages
+
+}
+
+// Round returns the percentages of the input counts, rounded as described by
+// the rounding mode. If the rounding mode is not valid, nil is returned.
+// RoundClosest, RoundUp and RoundDown round like the RoundFunc of math.Round,
+// math.Ceil and math.Trunc, while RoundNone and RoundLargestRemainder
+// handle all bins together.
+func (m RoundingMode) Round(layout Layout, pixels float64, counts []float64) []float64 {
+
+	switch m {
+	case RoundClosest:
+		return RoundFunc(math.Round).Round(layout, pixels, counts)
+	case RoundUp:
+		return RoundFunc(math.Ceil).Round(layout, pixels, counts)
+	case RoundDown:
+		return RoundFunc(math.Trunc).Round(layout, pixels, counts)
+	case RoundNone:
+		return exactPercentages(pixels, append([]float64(nil), counts...))
+	case RoundLargestRemainder:
+		return largestRemainder(pixels, append([]float64(nil), counts...))
+	default:
+		return nil
+	}
+
+}
+
+// Round returns the percentages of the input counts, rounded with the function.
+// For HSV layouts, bins that only differ by their Value level, including the gray bins,
+// are rounded cumulatively, making
+// sure that their sum is equal to the rounded value of the percentage of their sum.
+// For example, with two Value levels and n = HueLevels*SaturationLevels:
+// bins[i] + bins[i+n] = round((bins[i] + bins[i+n]) * 100 / pixels)
+func (f RoundFunc) Round(layout Layout, pixels float64, counts []float64) []float64 {
+
+	bins := append([]float64(nil), counts...)
+
+	cfg, ok := layout.(Config)
+	if !ok {
+
+		// Other layouts are rounded bin by bin.
+		for i := range bins {
+			bins[i] = f(bins[i] * 100 / pixels)
+		}
+
+		return bins
+
+	}
+
+	chromaticBins := cfg.HueLevels * cfg.SaturationLevels
+	for i := 0; i < chromaticBins; i++ {
+		f.roundCumulatively(bins, pixels, i, chromaticBins, cfg.ValueLevels)
+	}
+
+	// The gray bins only differ by their Value level, too.
+	f.roundCumulatively(bins, pixels, cfg.chromaticBins(), 1, cfg.GrayLevels)
+
+	return bins
+
+}
+
+// roundCumulatively rounds the given amount of bins, starting from first and
+// stepping by step, so that the sum of the first n bins is equal to the rounded
+// percentage of their sum, for every n.
+func (f RoundFunc) roundCumulatively(bins []float64, pixels float64, first, step, levels int) {
+
+	var cumulativeCount, previousPercentage float64
+	for level := 0; level < levels; level++ {
+
+		index := first + step*level
+		cumulativeCount += bins[index]
+		cumulativePercentage := f(cumulativeCount * 100 / pixels)
+		bins[index] = cumulativePercentage - previousPercentage
+		previousPercentage = cumulativePercentage
+
+	}
+
+}
+
+// exactPercentages normalizes histograms by the amount of pixels in the image,
+// without rounding.
+func exactPercentages(pixels float64, bins []float64) []float64 {
+
+	for i := range bins {
+		bins[i] = bins[i] * 100 / pixels
+	}
+
+	return bins
+
+}
+
+// largestRemainder normalizes histograms by the amount of pixels in the image
+// using the largest remainder method: every percentage is rounded down, then
+// the percentage points that are left are given, one each, to the bins with the
+// largest remainders. Ties are broken in favor of the lowest index.
+func largestRemainder(pixels float64, bins []float64) []float64 {
+
+	remainders := make([]float64, len(bins))
+	var exactTotal, roundedTotal float64
+	for i := range bins {
+
+		exact := bins[i] * 100 / pixels
+		bins[i] = math.Floor(exact)
+		remainders[i] = exact - bins[i]
+		exactTotal += exact
+		roundedTotal += bins[i]
+
+	}
+
+	indexes := make([]int, len(bins))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return remainders[indexes[i]] > remainders[indexes[j]]
+	})
+
+	leftover := int(math.Round(exactTotal - roundedTotal))
+	for i := 0; i < leftover && i < len(indexes); i++ {
+		bins[indexes[i]]++
+	}
+
+	return bins
+
+}
@@ -0,0 +1,199 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// eighthsImage returns an 8x1 image with five red pixels, two green ones and a blue one.
+func eighthsImage() image.Image {
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 1))
+	for x := 0; x < 8; x++ {
+		switch {
+		case x < 5:
+			img.Set(x, 0, color.RGBA{R: 255, A: 255})
+		case x < 7:
+			img.Set(x, 0, color.RGBA{G: 255, A: 255})
+		default:
+			img.Set(x, 0, color.RGBA{B: 255, A: 255})
+		}
+	}
+
+	return img
+
+}
+
+func TestRoundingMode(t *testing.T) {
+
+	tests := []struct {
+		mode  RoundingMode
+		valid bool
+		name  string
+	}{
+		{RoundClosest, true, "RoundClosest"},
+		{RoundUp, true, "RoundUp"},
+		{RoundDown, true, "RoundDown"},
+		{RoundNone, true, "RoundNone"},
+		{RoundLargestRemainder, true, "RoundLargestRemainder"},
+		{-1, false, "RoundingMode(-1)"},
+		{5, false, "RoundingMode(5)"},
+	}
+
+	for _, tt := range tests {
+
+		if got := tt.mode.Valid(); got != tt.valid {
+			t.Errorf("%v.Valid() = %v, want %v", tt.mode, got, tt.valid)
+		}
+
+		if got := tt.mode.String(); got != tt.name {
+			t.Errorf("RoundingMode(%d).String() = %v, want %v", int(tt.mode), got, tt.name)
+		}
+
+	}
+
+}
+
+func TestRounders(t *testing.T) {
+
+	// 62.5%, 25% and 12.5%.
+	h := must(New(eighthsImage(), Config32Bins, RoundNone))
+	red := Config32Bins.quantize(0, 100, 100)
+	green := Config32Bins.quantize(120, 100, 100)
+	blue := Config32Bins.quantize(240, 100, 100)
+
+	tests := []struct {
+		name    string
+		rounder Rounder
+		want    [3]float64
+	}{
+		{"RoundClosest", RoundClosest, [3]float64{63, 25, 13}},
+		{"RoundHalfEven", RoundHalfEven, [3]float64{62, 25, 12}},
+		{"Decimals 0", Decimals(0), [3]float64{63, 25, 13}},
+		{"Decimals 1", Decimals(1), [3]float64{62.5, 25, 12.5}},
+		{"Truncate", RoundFunc(math.Trunc), [3]float64{62, 25, 12}},
+		{"RoundLargestRemainder", RoundLargestRemainder, [3]float64{63, 25, 12}},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			rounded := h.Round(tt.rounder)
+			got := rounded.Percentages()
+			if got[red] != tt.want[0] || got[green] != tt.want[1] || got[blue] != tt.want[2] {
+				t.Errorf("Round() = %v, want %v", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(rounded.Counts(), h.Counts()) || rounded.Pixels() != h.Pixels() {
+				t.Errorf("Round() changed the counts to %v", rounded.Counts())
+			}
+
+		})
+
+	}
+
+	if got := h.Percentages(); got[red] != 62.5 {
+		t.Errorf("Round() changed the original Histogram to %v", got)
+	}
+
+}
+
+func TestDecimals(t *testing.T) {
+
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	img.Set(1, 0, color.RGBA{G: 255, A: 255})
+	img.Set(2, 0, color.RGBA{B: 255, A: 255})
+
+	got := must(New(img, Config64Bins, RoundNone)).Round(Decimals(2)).Percentages()
+	if got[Config64Bins.quantize(0, 100, 100)] != 33.33 {
+		t.Errorf("Round(Decimals(2)) = %v, want 33.33 for each color", got)
+	}
+
+	// Any Rounder can also be passed to the builders.
+	h, err := NewConcurrent(img, Config64Bins, Decimals(2))
+	if err != nil || !reflect.DeepEqual(h.Percentages(), got) {
+		t.Errorf("NewConcurrent() with Decimals(2) = %v, %v, want %v", h.Percentages(), err, got)
+	}
+
+}
+
+func TestStochastic(t *testing.T) {
+
+	img := lobsterCrop()
+	h := must(New(img, Config64Bins, RoundNone))
+	exact := h.Percentages()
+
+	// Each percentage is rounded either up or down, and the
+	// average of many roundings gets close to the exact value.
+	rounder := Stochastic(1)
+	const rounds = 2000
+	sums := make([]float64, len(exact))
+	for i := 0; i < rounds; i++ {
+
+		for bin, percentage := range h.Round(rounder).Percentages() {
+			sums[bin] += percentage
+		}
+
+	}
+
+	for bin := range exact {
+
+		if mean := sums[bin] / rounds; math.Abs(mean-exact[bin]) > 0.05 {
+			t.Errorf("bin %d: mean of stochastic rounding = %v, want about %v", bin, mean, exact[bin])
+		}
+
+	}
+
+	// The same seed gives the same results.
+	if got, want := h.Round(Stochastic(7)).Percentages(), h.Round(Stochastic(7)).Percentages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stochastic(7) = %v, then %v", got, want)
+	}
+
+}
+
+func TestStochasticNeverNegative(t *testing.T) {
+
+	// Bins that only differ by their Value level must not be
+	// rounded cumulatively, or they could become negative.
+	counts := make([]float64, Config64Bins.Bins())
+	counts[0], counts[1], counts[32] = 21, 179, 179
+
+	for seed := int64(0); seed < 200; seed++ {
+
+		for bin, percentage := range Stochastic(seed).Round(Config64Bins, 379, counts) {
+
+			exact := counts[bin] * 100 / 379
+			if percentage < 0 || (percentage != math.Floor(exact) && percentage != math.Ceil(exact)) {
+				t.Fatalf("seed %d: Stochastic().Round() bin %d = %v, want %v rounded up or down", seed, bin, percentage, exact)
+			}
+
+		}
+
+	}
+
+}
+
+func TestRoundFuncCumulative(t *testing.T) {
+
+	// With Config64Bins, the Value levels of each Hue and
+	// Saturation must sum to their rounded percentage.
+	h := must(New(lobsterCrop(), Config64Bins, RoundNone))
+	got := h.Round(RoundHalfEven).Percentages()
+	exact := h.Percentages()
+
+	for i := 0; i < 32; i++ {
+		if sum, want := got[i]+got[i+32], math.RoundToEven(exact[i]+exact[i+32]); sum != want {
+			t.Errorf("bins %d and %d sum to %v, want %v", i, i+32, sum, want)
+		}
+	}
+
+}
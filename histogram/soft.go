@@ -0,0 +1,131 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"image"
+	"math"
+)
+
+// SoftLayout is a Layout whose levels are ordered along each channel, so
+// that a pixel can be split among the bins that are closest to its color,
+// reducing the effect of the boundaries between bins: with hard assignment,
+// two colors that barely differ can end up in different bins.
+// Config, LabConfig and LChConfig are all SoftLayouts.
+type SoftLayout interface {
+	Layout
+
+	// Coordinates returns the position of a color, expressed with the
+	// alpha-premultiplied RGBA components returned by color.Color,
+	// along each channel of the layout, in the order used by Index.
+	Coordinates(r, g, b, a uint32) [3]Coordinate
+}
+
+// Coordinate is the position of a color along a channel of a SoftLayout.
+type Coordinate struct {
+
+	// Position is measured in levels: level i spans [i, i+1),
+	// so its center is at i+0.5.
+	Position float64
+
+	// Levels is the amount of levels a color can be split among.
+	Levels int
+
+	// Circular reports whether the channel wraps around, like a hue
+	// does, so that its last level is adjacent to the first one.
+	Circular bool
+}
+
+// Kernel decides how a pixel is split between the two levels of a channel
+// whose centers are closest to its position. It returns the share of a level
+// whose center is at distance d, in [0, 1] levels, from the position of the
+// pixel. The shares of the two levels are normalized to sum to 1, so every
+// pixel still counts once towards the histogram, spread over up to 8 bins.
+type Kernel func(d float64) float64
+
+// LinearKernel splits pixels linearly: a pixel on the center of
+// a level is counted entirely in it, while one halfway between
+// the centers of two levels is split equally between them.
+func LinearKernel(d float64) float64 {
+	return 1 - d
+}
+
+// CosineKernel splits pixels with a raised cosine, which keeps more of each
+// pixel in its closest level than LinearKernel does, while still splitting
+// the pixels that are halfway between two levels equally.
+func CosineKernel(d float64) float64 {
+	return (1 + math.Cos(math.Pi*d)) / 2
+}
+
+// softCounter returns a counter that splits every pixel among the bins of the
+// layout closest to its color with the kernel, weighting it as described by
+// the alpha policy.
+func softCounter(layout SoftLayout, kernel Kernel, alpha AlphaPolicy) counter {
+
+	return func(img image.Image, rect image.Rectangle, weights WeightFunc, bins []float64) float64 {
+
+		return eachWeightedPixel(img, rect, alpha, weights, func(r, g, b, a uint32, weight float64) {
+
+			var levels [3][2]int
+			var shares [3][2]float64
+			for i, coordinate := range layout.Coordinates(r, g, b, a) {
+				levels[i], shares[i] = splitCoordinate(coordinate, kernel)
+			}
+
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					for k := 0; k < 2; k++ {
+						if share := shares[0][i] * shares[1][j] * shares[2][k]; share > 0 {
+							bins[layout.Index(levels[0][i], levels[1][j], levels[2][k])] += weight * share
+						}
+					}
+				}
+			}
+
+		})
+
+	}
+
+}
+
+// splitCoordinate returns the two levels whose centers are closest to the
+// coordinate and the share of the pixel that each one of them gets from the
+// kernel. The levels out of range are wrapped around for circular channels
+// and clamped to the first or the last level for the others.
+func splitCoordinate(coordinate Coordinate, kernel Kernel) (levels [2]int, shares [2]float64) {
+
+	// d is the distance of the position from the center of the lower level.
+	lower := math.Floor(coordinate.Position - 0.5)
+	d := coordinate.Position - 0.5 - lower
+
+	levels = [2]int{int(lower), int(lower) + 1}
+	shares = [2]float64{kernel(d), kernel(1 - d)}
+
+	// A kernel that gives nothing to both levels
+	// falls back to the closest one.
+	if sum := shares[0] + shares[1]; sum > 0 {
+		shares[0], shares[1] = shares[0]/sum, shares[1]/sum
+	} else if d <= 0.5 {
+		shares = [2]float64{1, 0}
+	} else {
+		shares = [2]float64{0, 1}
+	}
+
+	for i, l := range levels {
+
+		switch {
+		case coordinate.Circular:
+			levels[i] = (l%coordinate.Levels + coordinate.Levels) % coordinate.Levels
+		case l < 0:
+			levels[i] = 0
+		case l >= coordinate.Levels:
+			levels[i] = coordinate.Levels - 1
+		}
+
+	}
+
+	return levels, shares
+
+}
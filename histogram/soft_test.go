@@ -0,0 +1,166 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSplitCoordinate(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		coordinate Coordinate
+		kernel     Kernel
+		levels     [2]int
+		shares     [2]float64
+	}{
+		{"Center of a level", Coordinate{Position: 2.5, Levels: 4}, LinearKernel, [2]int{2, 3}, [2]float64{1, 0}},
+		{"Between two levels", Coordinate{Position: 2, Levels: 4}, LinearKernel, [2]int{1, 2}, [2]float64{0.5, 0.5}},
+		{"Linear", Coordinate{Position: 1.75, Levels: 4}, LinearKernel, [2]int{1, 2}, [2]float64{0.75, 0.25}},
+		{"Cosine", Coordinate{Position: 1.75, Levels: 4}, CosineKernel, [2]int{1, 2}, [2]float64{(1 + math.Sqrt2/2) / 2, (1 - math.Sqrt2/2) / 2}},
+		{"Clamped below", Coordinate{Position: 0.2, Levels: 4}, LinearKernel, [2]int{0, 0}, [2]float64{0.3, 0.7}},
+		{"Clamped above", Coordinate{Position: 4, Levels: 4}, LinearKernel, [2]int{3, 3}, [2]float64{0.5, 0.5}},
+		{"Wrapped below", Coordinate{Position: 0.2, Levels: 8, Circular: true}, LinearKernel, [2]int{7, 0}, [2]float64{0.3, 0.7}},
+		{"Wrapped above", Coordinate{Position: 7.75, Levels: 8, Circular: true}, LinearKernel, [2]int{7, 0}, [2]float64{0.75, 0.25}},
+		{"Single level", Coordinate{Position: 0, Levels: 1, Circular: true}, LinearKernel, [2]int{0, 0}, [2]float64{0.5, 0.5}},
+		{"Null kernel", Coordinate{Position: 1.6, Levels: 4}, func(float64) float64 { return 0 }, [2]int{1, 2}, [2]float64{1, 0}},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			levels, shares := splitCoordinate(tt.coordinate, tt.kernel)
+			if levels != tt.levels || !closeTo(shares[0], tt.shares[0]) || !closeTo(shares[1], tt.shares[1]) {
+				t.Errorf("splitCoordinate(%+v) = %v, %v, want %v, %v", tt.coordinate, levels, shares, tt.levels, tt.shares)
+			}
+
+		})
+
+	}
+
+}
+
+// uniformImage returns a 4x4 image of the given color.
+func uniformImage(c color.Color) image.Image {
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < 16; i++ {
+		img.Set(i%4, i/4, c)
+	}
+
+	return img
+
+}
+
+// l1 returns the sum of the absolute differences of a and b.
+func l1(a, b []float64) float64 {
+
+	var sum float64
+	for i := range a {
+		sum += math.Abs(a[i] - b[i])
+	}
+
+	return sum
+
+}
+
+func TestSoftBinningHueBoundary(t *testing.T) {
+
+	// Hues of 51° and 52°, on the two sides of the boundary between the first two levels.
+	before := uniformImage(color.RGBA{R: 255, G: 217, A: 255})
+	after := uniformImage(color.RGBA{R: 255, G: 221, A: 255})
+
+	hard := l1(must(New(before, Config32Bins, RoundNone)).Percentages(), must(New(after, Config32Bins, RoundNone)).Percentages())
+	if hard != 200 {
+		t.Fatalf("hard binning distance = %v, want the colors in different bins", hard)
+	}
+
+	for name, kernel := range map[string]Kernel{"Linear": LinearKernel, "Cosine": CosineKernel} {
+
+		opts := Options{Kernel: kernel}
+		soft := l1(must(NewWithOptions(before, Config32Bins, RoundNone, opts)).Percentages(), must(NewWithOptions(after, Config32Bins, RoundNone, opts)).Percentages())
+		if soft > 10 {
+			t.Errorf("%s soft binning distance = %v, want the colors in the same bins", name, soft)
+		}
+
+	}
+
+}
+
+func TestSoftBinningEveryLayout(t *testing.T) {
+
+	img := lobsterCrop()
+	lastLegacyHue := Config32Bins.Index(Config32Bins.HueLevels-1, 0, 0)
+
+	for _, layout := range []Layout{Config32Bins, Config64Bins, ConfigSmithChang, Config64BinsV2, redCentered, Config64BinsGray, ConfigLab64Bins, ConfigLCh64Bins} {
+
+		for name, kernel := range map[string]Kernel{"Linear": LinearKernel, "Cosine": CosineKernel} {
+
+			opts := Options{Workers: 1, Kernel: kernel}
+			got := must(NewWithOptions(img, layout, RoundNone, opts))
+
+			var sum float64
+			for _, count := range got.Counts() {
+				sum += count
+			}
+
+			if pixels := float64(img.Bounds().Dx() * img.Bounds().Dy()); got.Pixels() != pixels || math.Abs(sum-pixels) > 1e-6 {
+				t.Errorf("%+v %s: pixels = %v, sum of counts = %v, want %v", layout, name, got.Pixels(), sum, pixels)
+			}
+
+			if layout == Config32Bins && got.Counts()[lastLegacyHue] != 0 {
+				t.Errorf("%+v %s: the last Hue level got %v pixels, want 0", layout, name, got.Counts()[lastLegacyHue])
+			}
+
+			engine := newTestEngine(t, layout, opts)
+			if fromEngine := must(engine.New(img, RoundNone)); l1(fromEngine.Counts(), got.Counts()) > 1e-6 {
+				t.Errorf("%+v %s: Engine.New() = %v, want %v", layout, name, fromEngine.Counts(), got.Counts())
+			}
+			engine.Close()
+
+		}
+
+	}
+
+}
+
+func TestSoftBinningWithLUT(t *testing.T) {
+
+	img := lobsterCrop()
+	opts := Options{Workers: 1, Kernel: LinearKernel}
+	want := must(NewWithOptions(img, Config64Bins, RoundNone, opts))
+
+	engine := newTestLUT(t, Config64Bins).NewEngine(opts)
+	defer engine.Close()
+
+	if got := must(engine.New(img, RoundNone)); l1(got.Counts(), want.Counts()) > 1e-6 {
+		t.Errorf("LUT Engine.New() = %v, want %v", got.Counts(), want.Counts())
+	}
+
+}
+
+// hardLayout hides the Coordinates method of a layout.
+type hardLayout struct {
+	Layout
+}
+
+func TestSoftBinningIgnoredByHardLayouts(t *testing.T) {
+
+	img := lobsterCrop()
+	layout := hardLayout{Config32Bins}
+
+	got := must(NewWithOptions(img, layout, RoundClosest, Options{Kernel: LinearKernel}))
+	want := must(New(img, layout, RoundClosest))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewWithOptions() with a kernel = %v, want %v", got.Counts(), want.Counts())
+	}
+
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+)
+
+// WeightFunc returns how much the pixel at (x, y) counts towards a histogram,
+// which accumulates the weights of the pixels into its bins instead of
+// counting each one of them once. The percentages are computed from the
+// total weight of the counted pixels, so only the ratios between the
+// weights matter. Pixels whose weight isn't positive are skipped.
+// A nil WeightFunc counts every pixel once.
+type WeightFunc func(x, y int) float64
+
+// WeightImage returns a WeightFunc that weights every pixel by the
+// luminance of weights at the same point, from 0 for black to 1 for white,
+// so that grayscale images can be used as weight maps. Pixels outside of the
+// bounds of weights are skipped. A nil weight image counts every pixel once.
+func WeightImage(weights image.Image) WeightFunc {
+
+	if weights == nil {
+		return nil
+	}
+
+	if gray, ok := weights.(*image.Gray); ok {
+
+		return func(x, y int) float64 {
+
+			if !(image.Point{X: x, Y: y}).In(gray.Rect) {
+				return 0
+			}
+
+			return float64(gray.Pix[gray.PixOffset(x, y)]) / 0xff
+
+		}
+
+	}
+
+	return func(x, y int) float64 {
+
+		if !(image.Point{X: x, Y: y}).In(weights.Bounds()) {
+			return 0
+		}
+
+		return float64(color.Gray16Model.Convert(weights.At(x, y)).(color.Gray16).Y) / 0xffff
+
+	}
+
+}
+
+// GaussianWeights returns a WeightFunc that weights the pixels in bounds with
+// a Gaussian centered in the middle of it, so that the pixels near the center
+// count more than the ones near the borders. sigma is the standard deviation
+// of the Gaussian, as a fraction of the width and of the height of bounds:
+// with a sigma of 0.5 a pixel in a corner weights about 37% of a pixel in
+// the center. Pixels outside of bounds are skipped.
+// It panics if sigma is not positive.
+func GaussianWeights(bounds image.Rectangle, sigma float64) WeightFunc {
+
+	if !(sigma > 0) {
+		panic("histogram: Gaussian weights with a non-positive sigma")
+	}
+
+	// The Gaussian is separable, so the weight of a pixel
+	// is the product of the weights of its column and row.
+	columns := gaussian(bounds.Min.X, bounds.Max.X, sigma)
+	rows := gaussian(bounds.Min.Y, bounds.Max.Y, sigma)
+
+	return func(x, y int) float64 {
+
+		if !(image.Point{X: x, Y: y}).In(bounds) {
+			return 0
+		}
+
+		return columns[x-bounds.Min.X] * rows[y-bounds.Min.Y]
+
+	}
+
+}
+
+// gaussian returns the weights of the coordinates in [min, max), given by a
+// Gaussian centered in the middle of the range, with a standard deviation of
+// sigma times its length.
+func gaussian(min, max int, sigma float64) []float64 {
+
+	if max <= min {
+		return nil
+	}
+
+	weights := make([]float64, max-min)
+	length := float64(max - min)
+	for i := range weights {
+		d := (float64(i) + 0.5 - length/2) / length
+		weights[i] = math.Exp(-d * d / (2 * sigma * sigma))
+	}
+
+	return weights
+
+}
+
+// NewWeighted returns the color Histogram of the input image, using the given
+// bin layout, where every pixel is counted with the weight given by weights.
+func NewWeighted(img image.Image, weights WeightFunc, layout Layout, rounder Rounder) (Histogram, error) {
+	return NewWeightedWithAlpha(img, weights, layout, rounder, nil)
+}
+
+// NewWeightedWithAlpha returns the color Histogram of the input image, where
+// every pixel is counted with the weight given by weights, like NewWeighted
+// does. The weight of a pixel is multiplied by the one given by the alpha policy.
+func NewWeightedWithAlpha(img image.Image, weights WeightFunc, layout Layout, rounder Rounder, alpha AlphaPolicy) (Histogram, error) {
+
+	if err := validate(layout, rounder); err != nil {
+		return Histogram{}, err
+	}
+
+	return newSequential(img, img.Bounds(), weights, layout, rounder, layoutCounter(layout, Options{Alpha: alpha}))
+
+}
+
+// NewWeightedConcurrent returns the color Histogram of the input image, where
+// every pixel is counted with the weight given by weights, like NewWeighted
+// does. This concurrent version splits the image into up to NumCPU
+// sub-images, using one goroutine per sub-image.
+func NewWeightedConcurrent(img image.Image, weights WeightFunc, layout Layout, rounder Rounder) (Histogram, error) {
+	return NewWeightedContext(context.Background(), img, weights, layout, rounder, Options{})
+}
+
+// NewWeightedContext returns the color Histogram of the input image, where
+// every pixel is counted with the weight given by weights, like NewWeighted
+// does, using the goroutines, tiling and alpha policy set in opts. The weight
+// of a pixel is multiplied by the one given by the alpha policy.
+// The goroutines stop as soon as ctx is done, in which case an empty
+// Histogram and ctx.Err() are returned.
+func NewWeightedContext(ctx context.Context, img image.Image, weights WeightFunc, layout Layout, rounder Rounder, opts Options) (Histogram, error) {
+
+	if err := validate(layout, rounder); err != nil {
+		return Histogram{}, err
+	}
+
+	return newConcurrent(ctx, img, img.Bounds(), weights, opts, layout, rounder, layoutCounter(layout, opts))
+
+}
@@ -0,0 +1,216 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNewWeighted(t *testing.T) {
+
+	img := lobsterCrop()
+	bounds := img.Bounds()
+	middle := (bounds.Min.X + bounds.Max.X) / 2
+	left := image.Rect(bounds.Min.X, bounds.Min.Y, middle, bounds.Max.Y)
+	right := image.Rect(middle, bounds.Min.Y, bounds.Max.X, bounds.Max.Y)
+
+	// The pixels on the left count three times as much as the ones on the right.
+	weights := func(x, y int) float64 {
+		if x < middle {
+			return 3
+		}
+		return 1
+	}
+
+	leftCounts := must(ForRegion(img, left, Config32Bins, RoundNone)).Counts()
+	rightCounts := must(ForRegion(img, right, Config32Bins, RoundNone)).Counts()
+	wantCounts := make([]float64, len(leftCounts))
+	for i := range wantCounts {
+		wantCounts[i] = 3*leftCounts[i] + rightCounts[i]
+	}
+	want := newHistogram(Config32Bins, RoundClosest, float64(3*left.Dx()*left.Dy()+right.Dx()*right.Dy()), wantCounts)
+
+	opts := Options{Workers: 2, Tiling: Tiling{Rows: 3, Columns: 2}}
+	engine := newTestEngine(t, Config32Bins, opts)
+	defer engine.Close()
+
+	lut := newTestLUT(t, Config32Bins)
+	lutEngine := lut.NewEngine(opts)
+	defer lutEngine.Close()
+
+	builders := map[string]func() (Histogram, error){
+		"NewWeighted": func() (Histogram, error) {
+			return NewWeighted(img, weights, Config32Bins, RoundClosest)
+		},
+		"NewWeightedConcurrent": func() (Histogram, error) {
+			return NewWeightedConcurrent(img, weights, Config32Bins, RoundClosest)
+		},
+		"NewWeightedContext": func() (Histogram, error) {
+			return NewWeightedContext(context.Background(), img, weights, Config32Bins, RoundClosest, opts)
+		},
+		"Engine.NewWeighted": func() (Histogram, error) {
+			return engine.NewWeighted(img, weights, RoundClosest)
+		},
+		"LUT.NewWeighted": func() (Histogram, error) {
+			return lut.NewWeighted(img, weights, RoundClosest)
+		},
+		"LUT.NewWeightedConcurrent": func() (Histogram, error) {
+			return lut.NewWeightedConcurrent(img, weights, RoundClosest)
+		},
+		"LUT Engine.NewWeighted": func() (Histogram, error) {
+			return lutEngine.NewWeighted(img, weights, RoundClosest)
+		},
+	}
+
+	for name, build := range builders {
+		if got, err := build(); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s() = %v, %v, want %v", name, got.Counts(), err, want.Counts())
+		}
+	}
+
+}
+
+func TestNewWeightedFractionalCounts(t *testing.T) {
+
+	img := productImage()
+	bounds := img.Bounds()
+	weights := GaussianWeights(bounds, 0.3)
+
+	var want float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			want += weights(x, y)
+		}
+	}
+
+	for _, got := range []Histogram{
+		must(NewWeighted(img, weights, Config32Bins, RoundNone)),
+		must(NewWeightedContext(context.Background(), img, weights, Config32Bins, RoundNone, Options{Tiling: Tiling{Size: image.Pt(3, 4)}})),
+	} {
+
+		if !closeTo(got.Pixels(), want) {
+			t.Errorf("Pixels() = %v, want the sum of the weights %v", got.Pixels(), want)
+		}
+
+		var sum float64
+		for _, count := range got.Counts() {
+			sum += count
+		}
+
+		if !closeTo(sum, want) {
+			t.Errorf("sum of Counts() = %v, want the sum of the weights %v", sum, want)
+		}
+
+	}
+
+}
+
+func TestNewWeightedWithAlpha(t *testing.T) {
+
+	img := productImage()
+	weights := func(x, y int) float64 { return 2 }
+	lut := newTestLUT(t, Config32Bins)
+
+	for name, got := range map[string]Histogram{
+		"NewWeightedContext":       must(NewWeightedContext(context.Background(), img, weights, Config32Bins, RoundNone, Options{Alpha: SkipTransparent})),
+		"NewWeightedWithAlpha":     must(NewWeightedWithAlpha(img, weights, Config32Bins, RoundNone, SkipTransparent)),
+		"LUT.NewWeightedWithAlpha": must(lut.NewWeightedWithAlpha(img, weights, RoundNone, SkipTransparent)),
+	} {
+
+		// The 26 pixels that aren't transparent are counted twice, the others are skipped.
+		if got.Pixels() != 52 {
+			t.Errorf("%s() with an alpha policy pixels = %v, want 52", name, got.Pixels())
+		}
+
+	}
+
+}
+
+func TestGaussianWeights(t *testing.T) {
+
+	bounds := image.Rect(-50, 10, 51, 111)
+	weights := GaussianWeights(bounds, 0.5)
+
+	if center := weights(0, 60); center != 1 {
+		t.Errorf("weight of the center = %v, want 1", center)
+	}
+
+	if corner, want := weights(-50, 10), math.Exp(-1); math.Abs(corner-want) > 0.01 {
+		t.Errorf("weight of a corner = %v, want about %v", corner, want)
+	}
+
+	for _, p := range []image.Point{{-51, 60}, {51, 60}, {0, 9}, {0, 111}} {
+		if got := weights(p.X, p.Y); got != 0 {
+			t.Errorf("weight of %v outside of the bounds = %v, want 0", p, got)
+		}
+	}
+
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+
+		if weights(x, 60) != weights(-x, 60) || weights(x, 60) != weights(0, 60+x) {
+			t.Fatalf("weights around %d are not symmetric", x)
+		}
+
+		if x < 0 && weights(x, 60) >= weights(x+1, 60) {
+			t.Fatalf("weight of %d is not less than the one of %d", x, x+1)
+		}
+
+	}
+
+}
+
+func TestGaussianWeightsNonPositiveSigma(t *testing.T) {
+
+	for _, sigma := range []float64{0, -0.5, math.NaN()} {
+
+		func() {
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("GaussianWeights() with a sigma of %v did not panic", sigma)
+				}
+			}()
+
+			GaussianWeights(image.Rect(0, 0, 10, 10), sigma)
+
+		}()
+
+	}
+
+}
+
+func TestWeightImage(t *testing.T) {
+
+	gray := image.NewGray(image.Rect(0, 0, 3, 1))
+	gray.SetGray(0, 0, color.Gray{Y: 0})
+	gray.SetGray(1, 0, color.Gray{Y: 0x33})
+	gray.SetGray(2, 0, color.Gray{Y: 0xff})
+
+	want := []float64{0, 0.2, 1, 0}
+	for _, weights := range []WeightFunc{WeightImage(gray), WeightImage(genericImage{gray})} {
+
+		for x, w := range want {
+			if got := weights(x, 0); !closeTo(got, w) {
+				t.Errorf("weight of (%d, 0) = %v, want %v", x, got, w)
+			}
+		}
+
+	}
+
+	if WeightImage(nil) != nil {
+		t.Errorf("WeightImage(nil) is not nil")
+	}
+
+	img := productImage()
+	if _, err := NewWeighted(img, WeightImage(image.NewGray(img.Bounds())), Config32Bins, RoundClosest); err != ErrEmptyImage {
+		t.Errorf("NewWeighted() with a black weight image error = %v, want %v", err, ErrEmptyImage)
+	}
+
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package naming
+
+// BasicNames are the 11 basic color terms of English
+// identified by Berlin and Kay, which Basic returns.
+var BasicNames = []string{
+	"black", "white", "gray", "red", "orange", "yellow",
+	"green", "blue", "purple", "pink", "brown",
+}
+
+// Basic returns the basic color term, one of BasicNames, of the color with
+// the given Hue, in degrees, Saturation and Value, in [0,100].
+// Dark colors are black, while colors with a low Saturation are white or
+// gray, depending on their Value. The others are named after their Hue, but
+// dark oranges and yellows are brown, while light, unsaturated reds and
+// bright colors between magenta and red are pink.
+func Basic(h, s, v float64) string {
+
+	switch {
+	case v < 20:
+		return "black"
+	case s < 15 && v >= 85:
+		return "white"
+	case s < 15:
+		return "gray"
+	}
+
+	switch {
+	case h < 15 || h >= 345:
+		if s < 50 && v >= 70 {
+			return "pink"
+		}
+		return "red"
+	case h < 45:
+		if v < 65 {
+			return "brown"
+		}
+		return "orange"
+	case h < 70:
+		if v < 50 {
+			return "brown"
+		}
+		return "yellow"
+	case h < 165:
+		return "green"
+	case h < 260:
+		return "blue"
+	case h < 300:
+		return "purple"
+	default:
+		if v < 60 {
+			return "purple"
+		}
+		return "pink"
+	}
+
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package naming
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestBasic(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		color color.RGBA
+		want  string
+	}{
+		{"Black", color.RGBA{A: 0xff}, "black"},
+		{"Very dark blue", color.RGBA{B: 0x30, A: 0xff}, "black"},
+		{"White", color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, "white"},
+		{"Gray", color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}, "gray"},
+		{"Slightly blue gray", color.RGBA{R: 0x70, G: 0x70, B: 0x80, A: 0xff}, "gray"},
+		{"Red", color.RGBA{R: 0xff, A: 0xff}, "red"},
+		{"Crimson", color.RGBA{R: 0xdc, G: 0x14, B: 0x3c, A: 0xff}, "red"},
+		{"Orange", color.RGBA{R: 0xff, G: 0xa5, A: 0xff}, "orange"},
+		{"Yellow", color.RGBA{R: 0xff, G: 0xff, A: 0xff}, "yellow"},
+		{"Green", color.RGBA{G: 0x80, A: 0xff}, "green"},
+		{"Blue", color.RGBA{B: 0xff, A: 0xff}, "blue"},
+		{"Teal", color.RGBA{G: 0x80, B: 0x80, A: 0xff}, "blue"},
+		{"Purple", color.RGBA{R: 0x80, B: 0x80, A: 0xff}, "purple"},
+		{"Indigo", color.RGBA{R: 0x4b, B: 0x82, A: 0xff}, "purple"},
+		{"Pink", color.RGBA{R: 0xff, G: 0xc0, B: 0xcb, A: 0xff}, "pink"},
+		{"Hot pink", color.RGBA{R: 0xff, G: 0x69, B: 0xb4, A: 0xff}, "pink"},
+		{"Saddle brown", color.RGBA{R: 0x8b, G: 0x45, B: 0x13, A: 0xff}, "brown"},
+		{"Dark yellow", color.RGBA{R: 0x60, G: 0x58, B: 0x10, A: 0xff}, "brown"},
+	}
+
+	for _, tt := range tests {
+		if got := Name(tt.color, Basic); got != tt.want {
+			t.Errorf("%s: Name(%v, Basic) = %q, want %q", tt.name, tt.color, got, tt.want)
+		}
+	}
+
+}
+
+func TestBasicNames(t *testing.T) {
+
+	names := make(map[string]bool)
+	for _, name := range BasicNames {
+		names[name] = true
+	}
+
+	if len(names) != 11 {
+		t.Fatalf("BasicNames has %d different names, want 11", len(names))
+	}
+
+	for h := 0.0; h < 360; h += 5 {
+		for s := 0.0; s <= 100; s += 5 {
+			for v := 0.0; v <= 100; v += 5 {
+				if name := Basic(h, s, v); !names[name] {
+					t.Fatalf("Basic(%v, %v, %v) = %q, which is not in BasicNames", h, s, v, name)
+				}
+			}
+		}
+	}
+
+}
@@ -0,0 +1,247 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package naming
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/AlessandroPomponio/hsv/conversion"
+)
+
+// CSSColors are the named colors of CSS Color Module Level 4, which
+// are the X11 colors with a few changes, sorted by name. Colors with
+// more than one name, like aqua and cyan, appear once for each name.
+var CSSColors = []Named{
+	{"aliceblue", color.RGBA{R: 0xf0, G: 0xf8, B: 0xff, A: 0xff}},
+	{"antiquewhite", color.RGBA{R: 0xfa, G: 0xeb, B: 0xd7, A: 0xff}},
+	{"aqua", color.RGBA{R: 0x00, G: 0xff, B: 0xff, A: 0xff}},
+	{"aquamarine", color.RGBA{R: 0x7f, G: 0xff, B: 0xd4, A: 0xff}},
+	{"azure", color.RGBA{R: 0xf0, G: 0xff, B: 0xff, A: 0xff}},
+	{"beige", color.RGBA{R: 0xf5, G: 0xf5, B: 0xdc, A: 0xff}},
+	{"bisque", color.RGBA{R: 0xff, G: 0xe4, B: 0xc4, A: 0xff}},
+	{"black", color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xff}},
+	{"blanchedalmond", color.RGBA{R: 0xff, G: 0xeb, B: 0xcd, A: 0xff}},
+	{"blue", color.RGBA{R: 0x00, G: 0x00, B: 0xff, A: 0xff}},
+	{"blueviolet", color.RGBA{R: 0x8a, G: 0x2b, B: 0xe2, A: 0xff}},
+	{"brown", color.RGBA{R: 0xa5, G: 0x2a, B: 0x2a, A: 0xff}},
+	{"burlywood", color.RGBA{R: 0xde, G: 0xb8, B: 0x87, A: 0xff}},
+	{"cadetblue", color.RGBA{R: 0x5f, G: 0x9e, B: 0xa0, A: 0xff}},
+	{"chartreuse", color.RGBA{R: 0x7f, G: 0xff, B: 0x00, A: 0xff}},
+	{"chocolate", color.RGBA{R: 0xd2, G: 0x69, B: 0x1e, A: 0xff}},
+	{"coral", color.RGBA{R: 0xff, G: 0x7f, B: 0x50, A: 0xff}},
+	{"cornflowerblue", color.RGBA{R: 0x64, G: 0x95, B: 0xed, A: 0xff}},
+	{"cornsilk", color.RGBA{R: 0xff, G: 0xf8, B: 0xdc, A: 0xff}},
+	{"crimson", color.RGBA{R: 0xdc, G: 0x14, B: 0x3c, A: 0xff}},
+	{"cyan", color.RGBA{R: 0x00, G: 0xff, B: 0xff, A: 0xff}},
+	{"darkblue", color.RGBA{R: 0x00, G: 0x00, B: 0x8b, A: 0xff}},
+	{"darkcyan", color.RGBA{R: 0x00, G: 0x8b, B: 0x8b, A: 0xff}},
+	{"darkgoldenrod", color.RGBA{R: 0xb8, G: 0x86, B: 0x0b, A: 0xff}},
+	{"darkgray", color.RGBA{R: 0xa9, G: 0xa9, B: 0xa9, A: 0xff}},
+	{"darkgreen", color.RGBA{R: 0x00, G: 0x64, B: 0x00, A: 0xff}},
+	{"darkgrey", color.RGBA{R: 0xa9, G: 0xa9, B: 0xa9, A: 0xff}},
+	{"darkkhaki", color.RGBA{R: 0xbd, G: 0xb7, B: 0x6b, A: 0xff}},
+	{"darkmagenta", color.RGBA{R: 0x8b, G: 0x00, B: 0x8b, A: 0xff}},
+	{"darkolivegreen", color.RGBA{R: 0x55, G: 0x6b, B: 0x2f, A: 0xff}},
+	{"darkorange", color.RGBA{R: 0xff, G: 0x8c, B: 0x00, A: 0xff}},
+	{"darkorchid", color.RGBA{R: 0x99, G: 0x32, B: 0xcc, A: 0xff}},
+	{"darkred", color.RGBA{R: 0x8b, G: 0x00, B: 0x00, A: 0xff}},
+	{"darksalmon", color.RGBA{R: 0xe9, G: 0x96, B: 0x7a, A: 0xff}},
+	{"darkseagreen", color.RGBA{R: 0x8f, G: 0xbc, B: 0x8f, A: 0xff}},
+	{"darkslateblue", color.RGBA{R: 0x48, G: 0x3d, B: 0x8b, A: 0xff}},
+	{"darkslategray", color.RGBA{R: 0x2f, G: 0x4f, B: 0x4f, A: 0xff}},
+	{"darkslategrey", color.RGBA{R: 0x2f, G: 0x4f, B: 0x4f, A: 0xff}},
+	{"darkturquoise", color.RGBA{R: 0x00, G: 0xce, B: 0xd1, A: 0xff}},
+	{"darkviolet", color.RGBA{R: 0x94, G: 0x00, B: 0xd3, A: 0xff}},
+	{"deeppink", color.RGBA{R: 0xff, G: 0x14, B: 0x93, A: 0xff}},
+	{"deepskyblue", color.RGBA{R: 0x00, G: 0xbf, B: 0xff, A: 0xff}},
+	{"dimgray", color.RGBA{R: 0x69, G: 0x69, B: 0x69, A: 0xff}},
+	{"dimgrey", color.RGBA{R: 0x69, G: 0x69, B: 0x69, A: 0xff}},
+	{"dodgerblue", color.RGBA{R: 0x1e, G: 0x90, B: 0xff, A: 0xff}},
+	{"firebrick", color.RGBA{R: 0xb2, G: 0x22, B: 0x22, A: 0xff}},
+	{"floralwhite", color.RGBA{R: 0xff, G: 0xfa, B: 0xf0, A: 0xff}},
+	{"forestgreen", color.RGBA{R: 0x22, G: 0x8b, B: 0x22, A: 0xff}},
+	{"fuchsia", color.RGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff}},
+	{"gainsboro", color.RGBA{R: 0xdc, G: 0xdc, B: 0xdc, A: 0xff}},
+	{"ghostwhite", color.RGBA{R: 0xf8, G: 0xf8, B: 0xff, A: 0xff}},
+	{"gold", color.RGBA{R: 0xff, G: 0xd7, B: 0x00, A: 0xff}},
+	{"goldenrod", color.RGBA{R: 0xda, G: 0xa5, B: 0x20, A: 0xff}},
+	{"gray", color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}},
+	{"green", color.RGBA{R: 0x00, G: 0x80, B: 0x00, A: 0xff}},
+	{"greenyellow", color.RGBA{R: 0xad, G: 0xff, B: 0x2f, A: 0xff}},
+	{"grey", color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}},
+	{"honeydew", color.RGBA{R: 0xf0, G: 0xff, B: 0xf0, A: 0xff}},
+	{"hotpink", color.RGBA{R: 0xff, G: 0x69, B: 0xb4, A: 0xff}},
+	{"indianred", color.RGBA{R: 0xcd, G: 0x5c, B: 0x5c, A: 0xff}},
+	{"indigo", color.RGBA{R: 0x4b, G: 0x00, B: 0x82, A: 0xff}},
+	{"ivory", color.RGBA{R: 0xff, G: 0xff, B: 0xf0, A: 0xff}},
+	{"khaki", color.RGBA{R: 0xf0, G: 0xe6, B: 0x8c, A: 0xff}},
+	{"lavender", color.RGBA{R: 0xe6, G: 0xe6, B: 0xfa, A: 0xff}},
+	{"lavenderblush", color.RGBA{R: 0xff, G: 0xf0, B: 0xf5, A: 0xff}},
+	{"lawngreen", color.RGBA{R: 0x7c, G: 0xfc, B: 0x00, A: 0xff}},
+	{"lemonchiffon", color.RGBA{R: 0xff, G: 0xfa, B: 0xcd, A: 0xff}},
+	{"lightblue", color.RGBA{R: 0xad, G: 0xd8, B: 0xe6, A: 0xff}},
+	{"lightcoral", color.RGBA{R: 0xf0, G: 0x80, B: 0x80, A: 0xff}},
+	{"lightcyan", color.RGBA{R: 0xe0, G: 0xff, B: 0xff, A: 0xff}},
+	{"lightgoldenrodyellow", color.RGBA{R: 0xfa, G: 0xfa, B: 0xd2, A: 0xff}},
+	{"lightgray", color.RGBA{R: 0xd3, G: 0xd3, B: 0xd3, A: 0xff}},
+	{"lightgreen", color.RGBA{R: 0x90, G: 0xee, B: 0x90, A: 0xff}},
+	{"lightgrey", color.RGBA{R: 0xd3, G: 0xd3, B: 0xd3, A: 0xff}},
+	{"lightpink", color.RGBA{R: 0xff, G: 0xb6, B: 0xc1, A: 0xff}},
+	{"lightsalmon", color.RGBA{R: 0xff, G: 0xa0, B: 0x7a, A: 0xff}},
+	{"lightseagreen", color.RGBA{R: 0x20, G: 0xb2, B: 0xaa, A: 0xff}},
+	{"lightskyblue", color.RGBA{R: 0x87, G: 0xce, B: 0xfa, A: 0xff}},
+	{"lightslategray", color.RGBA{R: 0x77, G: 0x88, B: 0x99, A: 0xff}},
+	{"lightslategrey", color.RGBA{R: 0x77, G: 0x88, B: 0x99, A: 0xff}},
+	{"lightsteelblue", color.RGBA{R: 0xb0, G: 0xc4, B: 0xde, A: 0xff}},
+	{"lightyellow", color.RGBA{R: 0xff, G: 0xff, B: 0xe0, A: 0xff}},
+	{"lime", color.RGBA{R: 0x00, G: 0xff, B: 0x00, A: 0xff}},
+	{"limegreen", color.RGBA{R: 0x32, G: 0xcd, B: 0x32, A: 0xff}},
+	{"linen", color.RGBA{R: 0xfa, G: 0xf0, B: 0xe6, A: 0xff}},
+	{"magenta", color.RGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff}},
+	{"maroon", color.RGBA{R: 0x80, G: 0x00, B: 0x00, A: 0xff}},
+	{"mediumaquamarine", color.RGBA{R: 0x66, G: 0xcd, B: 0xaa, A: 0xff}},
+	{"mediumblue", color.RGBA{R: 0x00, G: 0x00, B: 0xcd, A: 0xff}},
+	{"mediumorchid", color.RGBA{R: 0xba, G: 0x55, B: 0xd3, A: 0xff}},
+	{"mediumpurple", color.RGBA{R: 0x93, G: 0x70, B: 0xdb, A: 0xff}},
+	{"mediumseagreen", color.RGBA{R: 0x3c, G: 0xb3, B: 0x71, A: 0xff}},
+	{"mediumslateblue", color.RGBA{R: 0x7b, G: 0x68, B: 0xee, A: 0xff}},
+	{"mediumspringgreen", color.RGBA{R: 0x00, G: 0xfa, B: 0x9a, A: 0xff}},
+	{"mediumturquoise", color.RGBA{R: 0x48, G: 0xd1, B: 0xcc, A: 0xff}},
+	{"mediumvioletred", color.RGBA{R: 0xc7, G: 0x15, B: 0x85, A: 0xff}},
+	{"midnightblue", color.RGBA{R: 0x19, G: 0x19, B: 0x70, A: 0xff}},
+	{"mintcream", color.RGBA{R: 0xf5, G: 0xff, B: 0xfa, A: 0xff}},
+	{"mistyrose", color.RGBA{R: 0xff, G: 0xe4, B: 0xe1, A: 0xff}},
+	{"moccasin", color.RGBA{R: 0xff, G: 0xe4, B: 0xb5, A: 0xff}},
+	{"navajowhite", color.RGBA{R: 0xff, G: 0xde, B: 0xad, A: 0xff}},
+	{"navy", color.RGBA{R: 0x00, G: 0x00, B: 0x80, A: 0xff}},
+	{"oldlace", color.RGBA{R: 0xfd, G: 0xf5, B: 0xe6, A: 0xff}},
+	{"olive", color.RGBA{R: 0x80, G: 0x80, B: 0x00, A: 0xff}},
+	{"olivedrab", color.RGBA{R: 0x6b, G: 0x8e, B: 0x23, A: 0xff}},
+	{"orange", color.RGBA{R: 0xff, G: 0xa5, B: 0x00, A: 0xff}},
+	{"orangered", color.RGBA{R: 0xff, G: 0x45, B: 0x00, A: 0xff}},
+	{"orchid", color.RGBA{R: 0xda, G: 0x70, B: 0xd6, A: 0xff}},
+	{"palegoldenrod", color.RGBA{R: 0xee, G: 0xe8, B: 0xaa, A: 0xff}},
+	{"palegreen", color.RGBA{R: 0x98, G: 0xfb, B: 0x98, A: 0xff}},
+	{"paleturquoise", color.RGBA{R: 0xaf, G: 0xee, B: 0xee, A: 0xff}},
+	{"palevioletred", color.RGBA{R: 0xdb, G: 0x70, B: 0x93, A: 0xff}},
+	{"papayawhip", color.RGBA{R: 0xff, G: 0xef, B: 0xd5, A: 0xff}},
+	{"peachpuff", color.RGBA{R: 0xff, G: 0xda, B: 0xb9, A: 0xff}},
+	{"peru", color.RGBA{R: 0xcd, G: 0x85, B: 0x3f, A: 0xff}},
+	{"pink", color.RGBA{R: 0xff, G: 0xc0, B: 0xcb, A: 0xff}},
+	{"plum", color.RGBA{R: 0xdd, G: 0xa0, B: 0xdd, A: 0xff}},
+	{"powderblue", color.RGBA{R: 0xb0, G: 0xe0, B: 0xe6, A: 0xff}},
+	{"purple", color.RGBA{R: 0x80, G: 0x00, B: 0x80, A: 0xff}},
+	{"rebeccapurple", color.RGBA{R: 0x66, G: 0x33, B: 0x99, A: 0xff}},
+	{"red", color.RGBA{R: 0xff, G: 0x00, B: 0x00, A: 0xff}},
+	{"rosybrown", color.RGBA{R: 0xbc, G: 0x8f, B: 0x8f, A: 0xff}},
+	{"royalblue", color.RGBA{R: 0x41, G: 0x69, B: 0xe1, A: 0xff}},
+	{"saddlebrown", color.RGBA{R: 0x8b, G: 0x45, B: 0x13, A: 0xff}},
+	{"salmon", color.RGBA{R: 0xfa, G: 0x80, B: 0x72, A: 0xff}},
+	{"sandybrown", color.RGBA{R: 0xf4, G: 0xa4, B: 0x60, A: 0xff}},
+	{"seagreen", color.RGBA{R: 0x2e, G: 0x8b, B: 0x57, A: 0xff}},
+	{"seashell", color.RGBA{R: 0xff, G: 0xf5, B: 0xee, A: 0xff}},
+	{"sienna", color.RGBA{R: 0xa0, G: 0x52, B: 0x2d, A: 0xff}},
+	{"silver", color.RGBA{R: 0xc0, G: 0xc0, B: 0xc0, A: 0xff}},
+	{"skyblue", color.RGBA{R: 0x87, G: 0xce, B: 0xeb, A: 0xff}},
+	{"slateblue", color.RGBA{R: 0x6a, G: 0x5a, B: 0xcd, A: 0xff}},
+	{"slategray", color.RGBA{R: 0x70, G: 0x80, B: 0x90, A: 0xff}},
+	{"slategrey", color.RGBA{R: 0x70, G: 0x80, B: 0x90, A: 0xff}},
+	{"snow", color.RGBA{R: 0xff, G: 0xfa, B: 0xfa, A: 0xff}},
+	{"springgreen", color.RGBA{R: 0x00, G: 0xff, B: 0x7f, A: 0xff}},
+	{"steelblue", color.RGBA{R: 0x46, G: 0x82, B: 0xb4, A: 0xff}},
+	{"tan", color.RGBA{R: 0xd2, G: 0xb4, B: 0x8c, A: 0xff}},
+	{"teal", color.RGBA{R: 0x00, G: 0x80, B: 0x80, A: 0xff}},
+	{"thistle", color.RGBA{R: 0xd8, G: 0xbf, B: 0xd8, A: 0xff}},
+	{"tomato", color.RGBA{R: 0xff, G: 0x63, B: 0x47, A: 0xff}},
+	{"turquoise", color.RGBA{R: 0x40, G: 0xe0, B: 0xd0, A: 0xff}},
+	{"violet", color.RGBA{R: 0xee, G: 0x82, B: 0xee, A: 0xff}},
+	{"wheat", color.RGBA{R: 0xf5, G: 0xde, B: 0xb3, A: 0xff}},
+	{"white", color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}},
+	{"whitesmoke", color.RGBA{R: 0xf5, G: 0xf5, B: 0xf5, A: 0xff}},
+	{"yellow", color.RGBA{R: 0xff, G: 0xff, B: 0x00, A: 0xff}},
+	{"yellowgreen", color.RGBA{R: 0x9a, G: 0xcd, B: 0x32, A: 0xff}},
+}
+
+// Named is a color with its name.
+type Named struct {
+	Name  string
+	Color color.RGBA
+}
+
+// cssPoints are the points of CSSColors in the HSV cone.
+var cssPoints = conePoints(CSSColors)
+
+// CSS returns the name of the color of CSSColors closest to the one with
+// the given Hue, in degrees, Saturation and Value, in [0,100]. Colors are
+// compared with their Euclidean distance in the HSV cone, where the Hue of
+// the colors with a low Saturation or Value barely matters. Colors with
+// more than one name get the one that comes first.
+func CSS(h, s, v float64) string {
+	return nearest(CSSColors, cssPoints, h, s, v)
+}
+
+// Nearest returns a Namer that names colors after the closest color of the
+// given list, like CSS does. It panics if the list is empty.
+func Nearest(colors []Named) Namer {
+
+	if len(colors) == 0 {
+		panic("naming: no colors to choose from")
+	}
+
+	named := append([]Named(nil), colors...)
+	points := conePoints(named)
+
+	return func(h, s, v float64) string {
+		return nearest(named, points, h, s, v)
+	}
+
+}
+
+// nearest returns the name of the color whose point
+// is the closest to the one of the given HSV values.
+func nearest(colors []Named, points [][3]float64, h, s, v float64) string {
+
+	point := conePoint(h, s, v)
+	closest, closestDistance := 0, math.Inf(1)
+	for i, p := range points {
+
+		var d float64
+		for c := range p {
+			d += (p[c] - point[c]) * (p[c] - point[c])
+		}
+
+		if d < closestDistance {
+			closest, closestDistance = i, d
+		}
+
+	}
+
+	return colors[closest].Name
+
+}
+
+// conePoints returns the points of the colors in the HSV cone.
+func conePoints(colors []Named) [][3]float64 {
+
+	points := make([][3]float64, len(colors))
+	for i, c := range colors {
+		points[i] = conePoint(conversion.RGBAToHSV(c.Color.RGBA()))
+	}
+
+	return points
+
+}
+
+// conePoint returns the Cartesian coordinates of a color in the HSV cone:
+// the Value, followed by the chroma, that is the Saturation scaled by the
+// Value, times the cosine and the sine of the Hue, all of them in [0,100].
+func conePoint(h, s, v float64) [3]float64 {
+
+	chroma := s * v / 100
+	sin, cos := math.Sincos(h * math.Pi / 180)
+	return [3]float64{v, chroma * cos, chroma * sin}
+
+}
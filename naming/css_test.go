@@ -0,0 +1,64 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package naming
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestCSS(t *testing.T) {
+
+	// Each color is named after itself, or after the first name of its duplicates.
+	first := make(map[color.RGBA]string)
+	for _, named := range CSSColors {
+
+		if _, ok := first[named.Color]; !ok {
+			first[named.Color] = named.Name
+		}
+
+		if got := Name(named.Color, CSS); got != first[named.Color] {
+			t.Errorf("Name(%v, CSS) = %q, want %q", named.Color, got, first[named.Color])
+		}
+
+	}
+
+	if got := Name(color.RGBA{R: 0xfe, G: 0x01, B: 0x02, A: 0xff}, CSS); got != "red" {
+		t.Errorf("Name() of almost red = %q, want red", got)
+	}
+
+	if got := Name(color.Transparent, CSS); got != "black" {
+		t.Errorf("Name() of a transparent color = %q, want black", got)
+	}
+
+}
+
+func TestNearest(t *testing.T) {
+
+	colors := []Named{
+		{"light", color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}},
+		{"dark", color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}},
+	}
+
+	namer := Nearest(colors)
+	colors[0].Name = "changed"
+
+	if got := Name(color.RGBA{R: 0xa0, G: 0x90, B: 0x80, A: 0xff}, namer); got != "light" {
+		t.Errorf("Name() of a light color = %q, want light", got)
+	}
+
+	if got := Name(color.RGBA{B: 0x60, A: 0xff}, namer); got != "dark" {
+		t.Errorf("Name() of a dark color = %q, want dark", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Nearest() without colors doesn't panic")
+		}
+	}()
+
+	Nearest(nil)
+
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package naming provides methods to name colors and the bins of
+// histograms, so that an image can be summarized by the share of its
+// pixels covered by each color name, such as "blue": 40%.
+package naming
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+
+	"github.com/AlessandroPomponio/hsv/conversion"
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+// ErrUnsupportedLayout is returned when the bins of a layout can't be named,
+// since it is not a histogram.Config with gray bins.
+var ErrUnsupportedLayout = errors.New("naming: unsupported bin layout")
+
+// Namer returns the name of the color with the given Hue,
+// in degrees, Saturation and Value, in [0,100].
+// Basic and CSS are Namers.
+type Namer func(h, s, v float64) string
+
+// Name returns the name of a color, computing its HSV values with
+// conversion.RGBAToHSV. Fully transparent colors are black.
+func Name(c color.Color, namer Namer) string {
+	return namer(conversion.RGBAToHSV(c.RGBA()))
+}
+
+// BinName returns the name of the color at the center of the levels of the
+// given bin of the layout, which must be a histogram.Config with gray bins.
+// Without gray bins, the darkest and least saturated levels also hold the
+// blacks, grays and whites, so their centers aren't representative of their
+// colors: SummarizeImage names those bins after their actual colors instead.
+func BinName(layout histogram.Layout, bin int, namer Namer) (string, error) {
+
+	cfg, ok := layout.(histogram.Config)
+	if !ok || cfg.GrayLevels <= 0 {
+		return "", ErrUnsupportedLayout
+	}
+
+	return namer(cfg.Center(bin)), nil
+
+}
+
+// Summarize returns the percentage of the pixels of the histogram, in
+// [0,100], that falls in the bins with each name, like "blue": 40, naming
+// each bin with BinName. The percentages of the bins are rounded like
+// Percentages does, and names without pixels are left out.
+// An error is returned if the layout of the histogram is not supported.
+func Summarize(h histogram.Histogram, namer Namer) (map[string]float64, error) {
+
+	layout := h.Layout()
+	summary := make(map[string]float64)
+	for i, percentage := range h.Percentages() {
+
+		name, err := BinName(layout, i, namer)
+		if err != nil {
+			return nil, err
+		}
+
+		if percentage > 0 {
+			summary[name] += percentage
+		}
+
+	}
+
+	return summary, nil
+
+}
+
+// SummarizeImage returns the percentage of the pixels of the input image, in
+// [0,100], that have each name, like Summarize does. The pixels are grouped
+// by the bins of the layout, which can be any valid one, and every bin is
+// named after the mean color of its pixels, so the names are the ones of the
+// actual colors of the image. Names without pixels are left out.
+// An error is returned if the layout is not valid or the image is empty.
+func SummarizeImage(img image.Image, layout histogram.Layout, namer Namer) (map[string]float64, error) {
+	return SummarizeImageContext(context.Background(), img, layout, namer, histogram.Options{})
+}
+
+// SummarizeImageContext returns the percentage of the pixels of the input
+// image that have each name, like SummarizeImage does, computing the mean
+// color of every bin with histogram.NewWithMeans and the given options. The
+// goroutines stop as soon as ctx is done, in which case nil and ctx.Err()
+// are returned.
+func SummarizeImageContext(ctx context.Context, img image.Image, layout histogram.Layout, namer Namer, opts histogram.Options) (map[string]float64, error) {
+
+	h, means, err := histogram.NewWithMeans(ctx, img, layout, histogram.RoundNone, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := make(map[string]float64)
+	for i, fraction := range h.Fractions() {
+		if fraction > 0 {
+			summary[Name(means[i], namer)] += 100 * fraction
+		}
+	}
+
+	return summary, nil
+
+}
@@ -0,0 +1,226 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package naming
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+// unsupportedLayout hides the concrete type of a layout.
+type unsupportedLayout struct {
+	histogram.Layout
+}
+
+func TestBinName(t *testing.T) {
+
+	cfg := histogram.Config64BinsGray
+	cfg.RedCentered = true
+
+	tests := []struct {
+		name   string
+		layout histogram.Layout
+		index  int
+		want   string
+	}{
+		{"Darkest gray bin", cfg, cfg.Index(cfg.HueLevels, 0, 0), "black"},
+		{"Second gray bin", cfg, cfg.Index(cfg.HueLevels, 0, 1), "gray"},
+		{"Brightest gray bin", cfg, cfg.Index(cfg.HueLevels, 0, cfg.GrayLevels-1), "white"},
+		{"Saturated red", cfg, cfg.Index(0, 3, 1), "red"},
+		{"Saturated blue", cfg, cfg.Index(5, 3, 1), "blue"},
+	}
+
+	for _, tt := range tests {
+		if got, err := BinName(tt.layout, tt.index, Basic); err != nil || got != tt.want {
+			t.Errorf("%s: BinName() = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	// Without gray bins, the centers of the levels aren't representative of their colors.
+	for _, layout := range []histogram.Layout{unsupportedLayout{cfg}, histogram.Config64Bins, histogram.ConfigLab64Bins, histogram.ConfigLCh64Bins} {
+		if _, err := BinName(layout, 0, Basic); err != ErrUnsupportedLayout {
+			t.Errorf("BinName() with %+v error = %v, want %v", layout, err, ErrUnsupportedLayout)
+		}
+	}
+
+}
+
+func TestSummarize(t *testing.T) {
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < 16; i++ {
+		img.Set(i%4, i/4, color.RGBA{B: 0xff, A: 0xff})
+	}
+	for x := 0; x < 4; x++ {
+		img.Set(x, 0, color.White)
+	}
+
+	h, err := histogram.New(img, histogram.Config64BinsGray, histogram.RoundNone)
+	if err != nil {
+		t.Fatalf("histogram.New() error = %v", err)
+	}
+
+	want := map[string]float64{"blue": 75, "white": 25}
+	if got, err := Summarize(h, Basic); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize() = %v, %v, want %v", got, err, want)
+	}
+
+	unsupported, err := histogram.New(img, histogram.Config64Bins, histogram.RoundNone)
+	if err != nil {
+		t.Fatalf("histogram.New() error = %v", err)
+	}
+
+	if _, err := Summarize(unsupported, Basic); err != ErrUnsupportedLayout {
+		t.Errorf("Summarize() with an unsupported layout error = %v, want %v", err, ErrUnsupportedLayout)
+	}
+
+}
+
+func TestSummarizeImageSolidColors(t *testing.T) {
+
+	colors := map[string]color.Color{
+		"red":    color.RGBA{R: 0xff, A: 0xff},
+		"black":  color.Black,
+		"white":  color.White,
+		"yellow": color.RGBA{R: 0xff, G: 0xff, A: 0xff},
+	}
+
+	layouts := map[string]histogram.Layout{
+		"Config32Bins":     histogram.Config32Bins,
+		"Config64Bins":     histogram.Config64Bins,
+		"Config64BinsV2":   histogram.Config64BinsV2,
+		"Config64BinsGray": histogram.Config64BinsGray,
+		"ConfigLab64Bins":  histogram.ConfigLab64Bins,
+		"ConfigLCh64Bins":  histogram.ConfigLCh64Bins,
+	}
+
+	for layoutName, layout := range layouts {
+
+		for want, c := range colors {
+
+			img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+			draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+
+			got, err := SummarizeImage(img, layout, Basic)
+			if err != nil || !reflect.DeepEqual(got, map[string]float64{want: 100}) {
+				t.Errorf("%s: SummarizeImage() of a %s image = %v, %v, want %s at 100", layoutName, want, got, err, want)
+			}
+
+		}
+
+	}
+
+}
+
+func TestSummarizeImage(t *testing.T) {
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < 16; i++ {
+		img.Set(i%4, i/4, color.RGBA{B: 0xff, A: 0xff})
+	}
+	for x := 0; x < 4; x++ {
+		img.Set(x, 0, color.White)
+	}
+
+	want := map[string]float64{"blue": 75, "white": 25}
+	for _, layout := range []histogram.Layout{histogram.Config32Bins, histogram.Config64BinsGray, histogram.ConfigLCh64Bins} {
+		if got, err := SummarizeImage(img, layout, Basic); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("SummarizeImage() with %+v = %v, %v, want %v", layout, got, err, want)
+		}
+	}
+
+	// The transparent pixels are skipped, like the histogram does.
+	img.Set(0, 1, color.Transparent)
+	got, err := SummarizeImageContext(context.Background(), img, histogram.Config32Bins, Basic, histogram.Options{Alpha: histogram.SkipTransparent})
+	if want := map[string]float64{"blue": 100 * 11.0 / 15, "white": 100 * 4.0 / 15}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeImageContext() skipping transparent pixels = %v, %v, want %v", got, err, want)
+	}
+
+}
+
+func TestSummarizeImageErrors(t *testing.T) {
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+
+	if _, err := SummarizeImage(img, nil, Basic); err != histogram.ErrInvalidLayout {
+		t.Errorf("SummarizeImage() with a nil layout error = %v, want %v", err, histogram.ErrInvalidLayout)
+	}
+
+	if _, err := SummarizeImage(image.NewRGBA(image.Rectangle{}), histogram.Config32Bins, Basic); err != histogram.ErrEmptyImage {
+		t.Errorf("SummarizeImage() of an empty image error = %v, want %v", err, histogram.ErrEmptyImage)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, err := SummarizeImageContext(ctx, img, histogram.Config32Bins, Basic, histogram.Options{}); got != nil || err != context.Canceled {
+		t.Errorf("SummarizeImageContext() with a canceled context = %v, %v, want %v", got, err, context.Canceled)
+	}
+
+}
+
+func TestSummarizePicture(t *testing.T) {
+
+	file, err := os.Open("../pictures/lobster_medium.jpg")
+	if err != nil {
+		t.Fatalf("error while opening the picture: %v", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		t.Fatalf("error while decoding the picture: %v", err)
+	}
+
+	h, err := histogram.New(img, histogram.Config64BinsGray, histogram.RoundNone)
+	if err != nil {
+		t.Fatalf("histogram.New() error = %v", err)
+	}
+
+	for name, namer := range map[string]Namer{"Basic": Basic, "CSS": CSS} {
+
+		summaries := map[string]func() (map[string]float64, error){
+			"Summarize": func() (map[string]float64, error) {
+				return Summarize(h, namer)
+			},
+		}
+
+		for _, layout := range []histogram.Layout{histogram.Config64Bins, histogram.Config64BinsGray, histogram.ConfigLab64Bins, histogram.ConfigLCh64Bins} {
+			layout := layout
+			summaries[fmt.Sprintf("SummarizeImage with %+v", layout)] = func() (map[string]float64, error) {
+				return SummarizeImage(img, layout, namer)
+			}
+		}
+
+		for summaryName, summarize := range summaries {
+
+			summary, err := summarize()
+			if err != nil {
+				t.Fatalf("%s() and %s error = %v", summaryName, name, err)
+			}
+
+			var sum float64
+			for _, percentage := range summary {
+				sum += percentage
+			}
+
+			if math.Abs(sum-100) > 1e-9 {
+				t.Errorf("%s() and %s = %v, which sums to %v", summaryName, name, summary, sum)
+			}
+
+		}
+
+	}
+
+}
@@ -0,0 +1,224 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package palette
+
+import (
+	"context"
+	"image"
+	"sort"
+
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+// DefaultMergeDistance is the MergeDistance used when Options doesn't set one.
+const DefaultMergeDistance = 15
+
+// Options controls how the dominant colors of an image are extracted.
+// The zero value extracts them from a Config64BinsGray histogram,
+// computed with the default histogram.Options.
+type Options struct {
+
+	// Layout is the bin layout of the histogram the colors are
+	// extracted from. If it's nil, histogram.Config64BinsGray is used.
+	Layout histogram.Layout
+
+	// MergeDistance is the largest CIE76 color difference between the
+	// mean color of a bin and the one of an adjacent group of bins for the
+	// bin to be merged into the group. If it's 0, DefaultMergeDistance is
+	// used, while if it's negative bins are never merged.
+	MergeDistance float64
+
+	// Histogram controls how the histogram is computed, such as
+	// how many goroutines are used and how transparent pixels count.
+	Histogram histogram.Options
+}
+
+// mergeDistance returns the largest color difference of bins that are merged.
+func (o Options) mergeDistance() float64 {
+
+	if o.MergeDistance == 0 {
+		return DefaultMergeDistance
+	}
+
+	return o.MergeDistance
+
+}
+
+// layout returns the layout of the histogram.
+func (o Options) layout() histogram.Layout {
+
+	if o.Layout == nil {
+		return histogram.Config64BinsGray
+	}
+
+	return o.Layout
+
+}
+
+// Dominant returns up to k dominant colors of the input image, from the
+// one that covers the most pixels to the one that covers the least, or
+// all of them if k isn't positive. Each color is the mean color of the
+// pixels of a group of populated, adjacent bins of a histogram, so that
+// a color that falls on the boundary between two bins isn't split in two.
+// Bins are visited from the most populated one, and each bin is merged into
+// the adjacent group whose mean color is the closest to its own, if it's
+// close enough, or starts a new group.
+// An error is returned if the layout is not valid,
+// or if there are no pixels to extract the colors from.
+func Dominant(img image.Image, k int, opts Options) ([]Color, error) {
+	return DominantContext(context.Background(), img, k, opts)
+}
+
+// DominantContext returns up to k dominant colors of the input image, like
+// Dominant does. The goroutines that compute the histogram stop as soon as
+// ctx is done, in which case ctx.Err() is returned.
+func DominantContext(ctx context.Context, img image.Image, k int, opts Options) ([]Color, error) {
+
+	layout := opts.layout()
+	h, means, err := histogram.NewWithMeans(ctx, img, layout, histogram.RoundNone, opts.Histogram)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := h.Counts()
+	bins := make([]int, 0, len(counts))
+	for i, count := range counts {
+		if count > 0 {
+			bins = append(bins, i)
+		}
+	}
+
+	sort.SliceStable(bins, func(i, j int) bool {
+		return counts[bins[i]] > counts[bins[j]]
+	})
+
+	// groupOf is the index in groups of the group of each bin, or -1.
+	var groups []group
+	groupOf := make([]int, len(counts))
+	for i := range groupOf {
+		groupOf[i] = -1
+	}
+
+	maxDistance := opts.mergeDistance()
+	for n, bin := range bins {
+
+		mean := means[bin]
+		r, g, b := float64(mean.R), float64(mean.G), float64(mean.B)
+
+		closest, closestDistance := -1, maxDistance
+		for _, other := range bins[:n] {
+
+			if !adjacent(layout, bin, other) {
+				continue
+			}
+
+			candidate := &groups[groupOf[other]]
+			if d := distance(r, g, b, candidate.r, candidate.g, candidate.b); d <= closestDistance {
+				closest, closestDistance = groupOf[other], d
+			}
+
+		}
+
+		if closest < 0 {
+			closest = len(groups)
+			groups = append(groups, group{})
+		}
+
+		groupOf[bin] = closest
+		groups[closest].add(r, g, b, counts[bin])
+
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].pixels > groups[j].pixels
+	})
+
+	if k > 0 && k < len(groups) {
+		groups = groups[:k]
+	}
+
+	colors := make([]Color, len(groups))
+	for i, group := range groups {
+		colors[i] = newColor(group.r, group.g, group.b, group.pixels*100/h.Pixels())
+	}
+
+	return colors, nil
+
+}
+
+// group is a group of adjacent bins, with the mean color of its
+// pixels, expressed with 16-bit components, and their weight.
+type group struct {
+	r, g, b float64
+	pixels  float64
+}
+
+// add adds pixels of the given mean color to the group.
+func (g *group) add(r, gg, b, pixels float64) {
+
+	total := g.pixels + pixels
+	g.r = (g.r*g.pixels + r*pixels) / total
+	g.g = (g.g*g.pixels + gg*pixels) / total
+	g.b = (g.b*g.pixels + b*pixels) / total
+	g.pixels = total
+
+}
+
+// adjacent reports whether two different bins of the layout are next to each
+// other, that is if none of their levels differ by more than one. The Hue
+// levels of Config and LChConfig wrap around. The gray bins of a Config are
+// adjacent to the gray bins of the next and previous levels and to all the
+// bins with the lowest Saturation level.
+func adjacent(layout histogram.Layout, i, j int) bool {
+
+	i1, i2, i3 := layout.Levels(i)
+	j1, j2, j3 := layout.Levels(j)
+
+	var period1, period3 int
+	switch layout := layout.(type) {
+
+	case histogram.Config:
+
+		switch {
+		case layout.Gray(i) && layout.Gray(j):
+			return near(i3, j3, 0)
+		case layout.Gray(i):
+			return j2 == 0
+		case layout.Gray(j):
+			return i2 == 0
+		}
+
+		period1 = layout.HuePeriod()
+
+	case histogram.LChConfig:
+		period3 = layout.HueLevels
+
+	}
+
+	return near(i1, j1, period1) && near(i2, j2, 0) && near(i3, j3, period3)
+
+}
+
+// near reports whether two levels differ by at most one.
+// If period is positive, the levels wrap around after it,
+// so that the level period is the same as level 0.
+func near(a, b, period int) bool {
+
+	if period > 0 {
+		a, b = a%period, b%period
+	}
+
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+
+	if period > 0 && period-d < d {
+		d = period - d
+	}
+
+	return d <= 1
+
+}
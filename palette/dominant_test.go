@@ -0,0 +1,203 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package palette
+
+import (
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+// stripes returns a 10x10 image whose rows have the given colors,
+// each one repeated as many times as its amount of rows.
+func stripes(colors []color.Color, rows []int) image.Image {
+
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+
+	y := 0
+	for i, c := range colors {
+		for end := y + rows[i]; y < end; y++ {
+			for x := 0; x < 10; x++ {
+				img.Set(x, y, c)
+			}
+		}
+	}
+
+	return img
+
+}
+
+// lobster returns the decoded lobster picture.
+func lobster(t *testing.T) image.Image {
+
+	file, err := os.Open("../pictures/lobster_medium.jpg")
+	if err != nil {
+		t.Fatalf("error while opening the picture: %v", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		t.Fatalf("error while decoding the picture: %v", err)
+	}
+
+	return img
+
+}
+
+func TestDominant(t *testing.T) {
+
+	red := color.RGBA{R: 200, G: 20, B: 20, A: 255}
+	blue := color.RGBA{R: 20, G: 40, B: 200, A: 255}
+	green := color.RGBA{R: 30, G: 180, B: 40, A: 255}
+	img := stripes([]color.Color{green, red, blue}, []int{1, 6, 3})
+
+	got, err := Dominant(img, 0, Options{})
+	if err != nil {
+		t.Fatalf("Dominant() error = %v", err)
+	}
+
+	want := []struct {
+		rgb      color.RGBA
+		coverage float64
+	}{{red, 60}, {blue, 30}, {green, 10}}
+
+	if len(got) != len(want) {
+		t.Fatalf("Dominant() = %+v, want %d colors", got, len(want))
+	}
+
+	for i, w := range want {
+
+		if got[i].RGB != w.rgb || math.Abs(got[i].Coverage-w.coverage) > 1e-9 {
+			t.Errorf("Dominant()[%d] = %v with coverage %v, want %v with coverage %v", i, got[i].RGB, got[i].Coverage, w.rgb, w.coverage)
+		}
+
+		if s := got[i].S; s <= 0 || s > 100 {
+			t.Errorf("Dominant()[%d] Saturation = %v, want it in (0,100]", i, s)
+		}
+
+	}
+
+	if top, err := Dominant(img, 2, Options{}); err != nil || len(top) != 2 || top[0] != got[0] || top[1] != got[1] {
+		t.Errorf("Dominant() with k = 2 = %+v, %v, want %+v", top, err, got[:2])
+	}
+
+}
+
+func TestDominantMergesAdjacentBins(t *testing.T) {
+
+	// Hues of 44° and 46°, on the two sides of the boundary between the first two levels.
+	before := color.RGBA{R: 255, G: 187, A: 255}
+	after := color.RGBA{R: 255, G: 196, A: 255}
+	img := stripes([]color.Color{before, after}, []int{7, 3})
+
+	h, err := histogram.New(img, histogram.Config64BinsGray, histogram.RoundNone)
+	if err != nil {
+		t.Fatalf("histogram.New() error = %v", err)
+	}
+
+	populated := 0
+	for _, count := range h.Counts() {
+		if count > 0 {
+			populated++
+		}
+	}
+
+	if populated != 2 {
+		t.Fatalf("the colors fill %d bins, want 2", populated)
+	}
+
+	merged, err := Dominant(img, 0, Options{})
+	if err != nil || len(merged) != 1 || merged[0].Coverage != 100 {
+		t.Fatalf("Dominant() = %+v, %v, want a single color", merged, err)
+	}
+
+	// The mean is weighted by the amount of pixels of each color.
+	if want := uint8(math.Round(0.7*187 + 0.3*196)); merged[0].RGB.G != want {
+		t.Errorf("Dominant() = %v, want G = %d", merged[0].RGB, want)
+	}
+
+	if split, err := Dominant(img, 0, Options{MergeDistance: -1}); err != nil || len(split) != 2 || split[0].RGB != before || split[1].RGB != after {
+		t.Errorf("Dominant() without merging = %+v, %v, want %v and %v", split, err, before, after)
+	}
+
+}
+
+func TestAdjacent(t *testing.T) {
+
+	// With Version1, the last Hue level holds a Hue of 360°, which is the same as level 0.
+	v1, v2 := histogram.Config64Bins, histogram.Config64BinsV2
+	tests := []struct {
+		name   string
+		layout histogram.Config
+		i, j   int
+		want   bool
+	}{
+		{"Neighbouring levels", v1, v1.Index(2, 1, 1), v1.Index(3, 1, 1), true},
+		{"Distant levels", v1, v1.Index(2, 1, 1), v1.Index(4, 1, 1), false},
+		{"First and last level", v1, v1.Index(0, 1, 1), v1.Index(7, 1, 1), true},
+		{"Second and last level", v1, v1.Index(1, 1, 1), v1.Index(7, 1, 1), true},
+		{"Second to last and last level", v1, v1.Index(6, 1, 1), v1.Index(7, 1, 1), true},
+		{"Level before 360 and level 0", v1, v1.Index(6, 1, 1), v1.Index(0, 1, 1), true},
+		{"Level before 360 and level 1", v1, v1.Index(6, 1, 1), v1.Index(1, 1, 1), false},
+		{"Version2 first and last level", v2, v2.Index(0, 1, 1), v2.Index(7, 1, 1), true},
+		{"Version2 second and last level", v2, v2.Index(1, 1, 1), v2.Index(7, 1, 1), false},
+	}
+
+	for _, tt := range tests {
+		if got := adjacent(tt.layout, tt.i, tt.j); got != tt.want {
+			t.Errorf("%s: adjacent(%d, %d) = %v, want %v", tt.name, tt.i, tt.j, got, tt.want)
+		}
+	}
+
+}
+
+func TestDominantErrors(t *testing.T) {
+
+	img := stripes([]color.Color{color.White}, []int{10})
+
+	if _, err := Dominant(img, 0, Options{Layout: histogram.Config{}}); err != histogram.ErrInvalidLayout {
+		t.Errorf("Dominant() with an invalid layout error = %v, want %v", err, histogram.ErrInvalidLayout)
+	}
+
+	if _, err := Dominant(image.NewRGBA(image.Rectangle{}), 0, Options{}); err != histogram.ErrEmptyImage {
+		t.Errorf("Dominant() of an empty image error = %v, want %v", err, histogram.ErrEmptyImage)
+	}
+
+}
+
+func TestDominantOfPicture(t *testing.T) {
+
+	img := lobster(t)
+	for _, layout := range []histogram.Layout{histogram.Config64BinsGray, histogram.Config32Bins, histogram.ConfigLCh64Bins} {
+
+		colors, err := Dominant(img, 5, Options{Layout: layout})
+		if err != nil || len(colors) != 5 {
+			t.Fatalf("Dominant() with %+v = %+v, %v, want 5 colors", layout, colors, err)
+		}
+
+		var sum float64
+		for i, c := range colors {
+
+			if i > 0 && c.Coverage > colors[i-1].Coverage {
+				t.Errorf("Dominant() with %+v = %+v, want the colors sorted by coverage", layout, colors)
+			}
+
+			sum += c.Coverage
+
+		}
+
+		if sum > 100+1e-9 {
+			t.Errorf("Dominant() with %+v covers %v%% of the picture", layout, sum)
+		}
+
+	}
+
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package palette provides methods to extract the palette of an image, such
+// as its dominant colors, built on the histograms of the histogram package.
+package palette
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/AlessandroPomponio/hsv/conversion"
+)
+
+// Color is a color of the palette of an image, with the share of
+// the image it represents. Color implements color.Color, so that
+// palettes can be used as a color.Palette.
+type Color struct {
+
+	// RGB is the color, which is opaque.
+	RGB color.RGBA
+
+	// H, S and V are the Hue, in [0,360), the Saturation
+	// and the Value, in [0,100], of the color.
+	H, S, V float64
+
+	// Coverage is the percentage of the pixels of the image,
+	// in [0,100], that are represented by the color.
+	Coverage float64
+}
+
+// RGBA returns the alpha-premultiplied RGBA components of the color.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	return c.RGB.RGBA()
+}
+
+// newColor returns the Color of an opaque color, expressed with
+// 16-bit components, that covers the given percentage of the image.
+func newColor(r, g, b float64, coverage float64) Color {
+
+	rgb := color.RGBA{R: to8Bit(r), G: to8Bit(g), B: to8Bit(b), A: 0xff}
+	h, s, v := conversion.RGBAToHSVExact(rgb.RGBA())
+
+	return Color{RGB: rgb, H: h, S: s, V: v, Coverage: coverage}
+
+}
+
+// to8Bit rounds a 16-bit component to 8 bits.
+func to8Bit(x float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(x, 0xffff)) / 0x101))
+}
+
+// distance returns the CIE76 color difference, that is the Euclidean
+// distance in CIELAB, of two opaque colors with 16-bit components.
+func distance(r1, g1, b1, r2, g2, b2 float64) float64 {
+
+	l1, a1, bb1 := conversion.RGBAToLab(uint32(r1), uint32(g1), uint32(b1), 0xffff)
+	l2, a2, bb2 := conversion.RGBAToLab(uint32(r2), uint32(g2), uint32(b2), 0xffff)
+
+	return math.Sqrt((l1-l2)*(l1-l2) + (a1-a2)*(a1-a2) + (bb1-bb2)*(bb1-bb2))
+
+}
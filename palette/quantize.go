@@ -0,0 +1,441 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package palette
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/AlessandroPomponio/hsv/conversion"
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+var (
+	// ErrInvalidAmount is returned when a palette of less than one color is requested.
+	ErrInvalidAmount = errors.New("palette: invalid amount of colors")
+
+	// ErrInvalidOptions is returned when the method or the color space are not valid.
+	ErrInvalidOptions = errors.New("palette: invalid method or color space")
+)
+
+// DefaultIterations is the amount of k-means iterations used
+// when QuantizeOptions doesn't set one.
+const DefaultIterations = 20
+
+// Method is an algorithm that reduces the colors of an image to a palette.
+type Method int
+
+const (
+	// MedianCut repeatedly splits the group of colors with the largest
+	// variance in two, at the weighted median of the channel along
+	// which they vary the most, and returns the mean of each group.
+	MedianCut Method = iota
+
+	// KMeans clusters the colors with Lloyd's algorithm, choosing the
+	// initial centers with k-means++, and returns the center of each cluster.
+	KMeans
+)
+
+// Space is a color space colors are compared in while building a palette.
+type Space int
+
+const (
+	// Lab compares colors with their Euclidean distance in
+	// CIELAB, which approximates the perceived difference.
+	Lab Space = iota
+
+	// HSV compares colors with their Euclidean distance in the HSV
+	// cylinder, so that the Hue wraps around and the Hue of the colors
+	// with a low Saturation barely matters.
+	HSV
+)
+
+// sampleLayout is the layout of the histogram the colors of an image are
+// sampled from: each of its populated bins is a sample, whose color is the
+// mean color of its pixels, so that images of any size are quantized in
+// about the same time once the histogram has been computed.
+var sampleLayout = histogram.LabConfig{LightnessLevels: 32, ALevels: 32, BLevels: 32}
+
+// QuantizeOptions controls how the palette of an image is built.
+// The zero value uses MedianCut in the Lab color space.
+type QuantizeOptions struct {
+
+	// Method is the algorithm used to build the palette.
+	Method Method
+
+	// Space is the color space colors are compared in.
+	Space Space
+
+	// Seed seeds the choice of the initial KMeans centers,
+	// so that the same seed always returns the same palette.
+	Seed int64
+
+	// Iterations is the maximum amount of KMeans iterations.
+	// If it's not positive, DefaultIterations is used.
+	Iterations int
+
+	// Histogram controls how the colors are sampled, such as
+	// how many goroutines are used and how transparent pixels count.
+	Histogram histogram.Options
+}
+
+// iterations returns the maximum amount of k-means iterations.
+func (o QuantizeOptions) iterations() int {
+
+	if o.Iterations > 0 {
+		return o.Iterations
+	}
+
+	return DefaultIterations
+
+}
+
+// Quantize returns a palette of up to k colors that represent the input
+// image, from the one that covers the most pixels to the one that covers
+// the least. Fewer colors are returned if the image doesn't have enough.
+// An error is returned if k isn't positive, if the method or the color
+// space are not valid, or if there are no pixels to build the palette from.
+func Quantize(img image.Image, k int, opts QuantizeOptions) ([]Color, error) {
+	return QuantizeContext(context.Background(), img, k, opts)
+}
+
+// QuantizeContext returns a palette of up to k colors of the input image,
+// like Quantize does. The goroutines that sample the colors of the image
+// stop as soon as ctx is done, in which case ctx.Err() is returned.
+func QuantizeContext(ctx context.Context, img image.Image, k int, opts QuantizeOptions) ([]Color, error) {
+
+	if k < 1 {
+		return nil, ErrInvalidAmount
+	}
+
+	if opts.Method != MedianCut && opts.Method != KMeans || opts.Space != Lab && opts.Space != HSV {
+		return nil, ErrInvalidOptions
+	}
+
+	h, means, err := histogram.NewWithMeans(ctx, img, sampleLayout, histogram.RoundNone, opts.Histogram)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []sample
+	for i, count := range h.Counts() {
+		if count > 0 {
+			samples = append(samples, sample{point: opts.Space.point(means[i]), weight: count})
+		}
+	}
+
+	var clusters []sample
+	if opts.Method == KMeans {
+		clusters = kMeans(samples, k, opts.iterations(), opts.Seed)
+	} else {
+		clusters = medianCut(samples, k)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].weight > clusters[j].weight
+	})
+
+	colors := make([]Color, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.weight > 0 {
+			colors = append(colors, opts.Space.color(cluster.point, cluster.weight*100/h.Pixels()))
+		}
+	}
+
+	return colors, nil
+
+}
+
+// sample is a color, expressed in the coordinates of a Space,
+// with the amount of pixels it represents.
+type sample struct {
+	point  [3]float64
+	weight float64
+}
+
+// point returns the coordinates of an opaque color in the Space.
+// The HSV cylinder is mapped to Cartesian coordinates, with the Value
+// first, followed by the Saturation scaled by the cosine and the sine
+// of the Hue, all of them in the same range of the CIELAB channels.
+func (s Space) point(c color.RGBA64) [3]float64 {
+
+	if s == HSV {
+		h, saturation, v := conversion.RGBAToHSVExact(c.RGBA())
+		sin, cos := math.Sincos(h * math.Pi / 180)
+		return [3]float64{v, saturation * cos, saturation * sin}
+	}
+
+	l, a, b := conversion.RGBAToLab(c.RGBA())
+	return [3]float64{l, a, b}
+
+}
+
+// color returns the Color with the given coordinates in the Space.
+func (s Space) color(point [3]float64, coverage float64) Color {
+
+	var r, g, b uint32
+	if s == HSV {
+		h := math.Atan2(point[2], point[1]) * 180 / math.Pi
+		r, g, b, _ = conversion.HSVToRGBA(h, math.Hypot(point[1], point[2]), point[0])
+	} else {
+		r, g, b, _ = conversion.LabToRGBA(point[0], point[1], point[2])
+	}
+
+	return newColor(float64(r), float64(g), float64(b), coverage)
+
+}
+
+// squaredDistance returns the squared Euclidean distance of two points.
+func squaredDistance(p, q [3]float64) float64 {
+
+	var sum float64
+	for i := range p {
+		sum += (p[i] - q[i]) * (p[i] - q[i])
+	}
+
+	return sum
+
+}
+
+// mean returns the weighted mean of the samples, with their total weight,
+// and the weighted sum of the squared deviations along each channel.
+func mean(samples []sample) (center sample, deviations [3]float64) {
+
+	for _, s := range samples {
+		center.weight += s.weight
+		for i := range center.point {
+			center.point[i] += s.point[i] * s.weight
+		}
+	}
+
+	if center.weight <= 0 {
+		return center, deviations
+	}
+
+	for i := range center.point {
+		center.point[i] /= center.weight
+	}
+
+	for _, s := range samples {
+		for i := range deviations {
+			deviations[i] += (s.point[i] - center.point[i]) * (s.point[i] - center.point[i]) * s.weight
+		}
+	}
+
+	return center, deviations
+
+}
+
+// medianCut divides the samples into up to k groups, always splitting the
+// one whose samples deviate the most from their mean, and returns their means.
+func medianCut(samples []sample, k int) []sample {
+
+	boxes := [][]sample{samples}
+	for len(boxes) < k {
+
+		largest, channel, largestDeviation := -1, 0, 0.0
+		for i, box := range boxes {
+
+			if len(box) < 2 {
+				continue
+			}
+
+			_, deviations := mean(box)
+			for c, deviation := range deviations {
+				if deviation > largestDeviation {
+					largest, channel, largestDeviation = i, c, deviation
+				}
+			}
+
+		}
+
+		// Every box holds a single color.
+		if largest < 0 {
+			break
+		}
+
+		box := boxes[largest]
+		sort.SliceStable(box, func(i, j int) bool {
+			return box[i].point[channel] < box[j].point[channel]
+		})
+
+		cut := medianIndex(box)
+		boxes[largest] = box[:cut]
+		boxes = append(boxes, box[cut:])
+
+	}
+
+	means := make([]sample, len(boxes))
+	for i, box := range boxes {
+		means[i], _ = mean(box)
+	}
+
+	return means
+
+}
+
+// medianIndex returns the index that splits the sorted samples into
+// two non-empty halves whose weights are as close as possible.
+func medianIndex(samples []sample) int {
+
+	var total float64
+	for _, s := range samples {
+		total += s.weight
+	}
+
+	var cumulative float64
+	for i, s := range samples[:len(samples)-1] {
+
+		// Include the sample if it brings the cumulative weight closer to half.
+		if cumulative += s.weight; cumulative >= total/2 {
+			if i > 0 && cumulative-total/2 > total/2-(cumulative-s.weight) {
+				return i
+			}
+			return i + 1
+		}
+
+	}
+
+	return len(samples) - 1
+
+}
+
+// kMeans clusters the samples into up to k clusters with Lloyd's algorithm,
+// initialized with k-means++ using the given seed, and returns their centers.
+func kMeans(samples []sample, k, iterations int, seed int64) []sample {
+
+	if len(samples) <= k {
+		return append([]sample(nil), samples...)
+	}
+
+	centers := seedCenters(samples, k, rand.New(rand.NewSource(seed)))
+	clusterOf := make([]int, len(samples))
+	for i := range clusterOf {
+		clusterOf[i] = -1
+	}
+
+	for iteration := 0; iteration < iterations; iteration++ {
+
+		changed := false
+		for i, s := range samples {
+			if closest, _ := nearest(s.point, centers); closest != clusterOf[i] {
+				clusterOf[i], changed = closest, true
+			}
+		}
+
+		if !changed {
+			break
+		}
+
+		sums := make([]sample, len(centers))
+		for i, s := range samples {
+			sum := &sums[clusterOf[i]]
+			sum.weight += s.weight
+			for c := range sum.point {
+				sum.point[c] += s.point[c] * s.weight
+			}
+		}
+
+		// Clusters left without samples keep their previous center.
+		for i, sum := range sums {
+			centers[i].weight = sum.weight
+			if sum.weight <= 0 {
+				continue
+			}
+			for c := range sum.point {
+				centers[i].point[c] = sum.point[c] / sum.weight
+			}
+		}
+
+	}
+
+	return centers
+
+}
+
+// seedCenters chooses k different samples as the initial centers with
+// k-means++: the first one is chosen with a probability proportional to its
+// weight, and every other one with a probability proportional to its weight
+// times its squared distance from the closest center chosen so far.
+func seedCenters(samples []sample, k int, random *rand.Rand) []sample {
+
+	probabilities := make([]float64, len(samples))
+	for i, s := range samples {
+		probabilities[i] = s.weight
+	}
+
+	centers := make([]sample, 0, k)
+	for len(centers) < k {
+
+		// Every sample is already a center.
+		chosen := choose(probabilities, random)
+		if chosen < 0 {
+			break
+		}
+
+		centers = append(centers, sample{point: samples[chosen].point})
+
+		for i, s := range samples {
+			_, d := nearest(s.point, centers)
+			probabilities[i] = s.weight * d
+		}
+
+	}
+
+	return centers
+
+}
+
+// choose returns an index chosen with a probability proportional to its
+// value, or the first index with a positive value if the random number
+// falls out of the total because of rounding errors, or -1 if there's none.
+func choose(probabilities []float64, random *rand.Rand) int {
+
+	var total float64
+	for _, p := range probabilities {
+		total += p
+	}
+
+	target := random.Float64() * total
+	first := -1
+	for i, p := range probabilities {
+
+		if p <= 0 {
+			continue
+		}
+
+		if first < 0 {
+			first = i
+		}
+
+		if target -= p; target < 0 {
+			return i
+		}
+
+	}
+
+	return first
+
+}
+
+// nearest returns the index of the center closest to the
+// point and the squared distance between the two.
+func nearest(point [3]float64, centers []sample) (int, float64) {
+
+	closest, closestDistance := 0, math.Inf(1)
+	for i, center := range centers {
+		if d := squaredDistance(point, center.point); d < closestDistance {
+			closest, closestDistance = i, d
+		}
+	}
+
+	return closest, closestDistance
+
+}
@@ -0,0 +1,186 @@
+// Copyright 2019 Alessandro Pomponio. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package palette
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/AlessandroPomponio/hsv/histogram"
+)
+
+// methods returns every combination of method and color space.
+func methods() map[string]QuantizeOptions {
+
+	return map[string]QuantizeOptions{
+		"MedianCut Lab": {Method: MedianCut, Space: Lab},
+		"MedianCut HSV": {Method: MedianCut, Space: HSV},
+		"KMeans Lab":    {Method: KMeans, Space: Lab},
+		"KMeans HSV":    {Method: KMeans, Space: HSV},
+	}
+
+}
+
+// nearRGB reports whether no component of the two colors differs by more than 2.
+func nearRGB(a, b color.RGBA) bool {
+
+	for _, d := range []int{int(a.R) - int(b.R), int(a.G) - int(b.G), int(a.B) - int(b.B)} {
+		if d < -2 || d > 2 {
+			return false
+		}
+	}
+
+	return a.A == b.A
+
+}
+
+func TestQuantize(t *testing.T) {
+
+	red := color.RGBA{R: 200, G: 20, B: 20, A: 255}
+	blue := color.RGBA{R: 20, G: 40, B: 200, A: 255}
+	green := color.RGBA{R: 30, G: 180, B: 40, A: 255}
+	img := stripes([]color.Color{green, red, blue}, []int{1, 6, 3})
+
+	want := []struct {
+		rgb      color.RGBA
+		coverage float64
+	}{{red, 60}, {blue, 30}, {green, 10}}
+
+	for name, opts := range methods() {
+
+		for _, k := range []int{3, 5} {
+
+			got, err := Quantize(img, k, opts)
+			if err != nil || len(got) != len(want) {
+				t.Fatalf("%s: Quantize() with k = %d = %+v, %v, want %d colors", name, k, got, err, len(want))
+			}
+
+			for i, w := range want {
+				if !nearRGB(got[i].RGB, w.rgb) || math.Abs(got[i].Coverage-w.coverage) > 1e-9 {
+					t.Errorf("%s: Quantize()[%d] = %v with coverage %v, want %v with coverage %v", name, i, got[i].RGB, got[i].Coverage, w.rgb, w.coverage)
+				}
+			}
+
+		}
+
+		got, err := Quantize(img, 2, opts)
+		if err != nil || len(got) != 2 || got[0].Coverage+got[1].Coverage != 100 {
+			t.Errorf("%s: Quantize() with k = 2 = %+v, %v, want 2 colors that cover the image", name, got, err)
+		}
+
+	}
+
+}
+
+func TestQuantizeOfPicture(t *testing.T) {
+
+	img := lobster(t)
+	for name, opts := range methods() {
+
+		colors, err := Quantize(img, 8, opts)
+		if err != nil || len(colors) != 8 {
+			t.Fatalf("%s: Quantize() = %+v, %v, want 8 colors", name, colors, err)
+		}
+
+		var sum float64
+		for i, c := range colors {
+
+			if i > 0 && c.Coverage > colors[i-1].Coverage {
+				t.Errorf("%s: Quantize() = %+v, want the colors sorted by coverage", name, colors)
+			}
+
+			sum += c.Coverage
+
+		}
+
+		if math.Abs(sum-100) > 1e-6 {
+			t.Errorf("%s: Quantize() covers %v%% of the picture, want 100%%", name, sum)
+		}
+
+		// The palette doesn't depend on how the colors are sampled.
+		concurrent := opts
+		concurrent.Histogram = histogram.Options{Workers: 3, Tiling: histogram.Tiling{Rows: 5, Columns: 2}}
+		if again, err := QuantizeContext(context.Background(), img, 8, concurrent); err != nil || !reflect.DeepEqual(again, colors) {
+			t.Errorf("%s: QuantizeContext() = %+v, %v, want %+v", name, again, err, colors)
+		}
+
+	}
+
+}
+
+func TestQuantizeSeed(t *testing.T) {
+
+	img := lobster(t)
+	opts := QuantizeOptions{Method: KMeans, Seed: 42}
+
+	first, err := Quantize(img, 6, opts)
+	if err != nil {
+		t.Fatalf("Quantize() error = %v", err)
+	}
+
+	if second, err := Quantize(img, 6, opts); err != nil || !reflect.DeepEqual(first, second) {
+		t.Errorf("Quantize() with the same seed = %+v, %v, want %+v", second, err, first)
+	}
+
+}
+
+func TestQuantizeErrors(t *testing.T) {
+
+	img := stripes([]color.Color{color.White}, []int{10})
+
+	tests := []struct {
+		name string
+		img  image.Image
+		k    int
+		opts QuantizeOptions
+		want error
+	}{
+		{"No colors", img, 0, QuantizeOptions{}, ErrInvalidAmount},
+		{"Invalid method", img, 3, QuantizeOptions{Method: KMeans + 1}, ErrInvalidOptions},
+		{"Invalid space", img, 3, QuantizeOptions{Space: -1}, ErrInvalidOptions},
+		{"Empty image", image.NewRGBA(image.Rectangle{}), 3, QuantizeOptions{}, histogram.ErrEmptyImage},
+	}
+
+	for _, tt := range tests {
+		if _, err := Quantize(tt.img, tt.k, tt.opts); err != tt.want {
+			t.Errorf("%s: Quantize() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+}
+
+func TestMedianIndex(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		weights []float64
+		want    int
+	}{
+		{"Equal weights", []float64{1, 1, 1, 1}, 2},
+		{"Odd amount", []float64{1, 1, 1}, 2},
+		{"Heavy first", []float64{10, 1, 1}, 1},
+		{"Heavy last", []float64{1, 1, 10}, 2},
+		{"Heavy middle", []float64{1, 10, 2}, 2},
+		{"Two samples", []float64{5, 1}, 1},
+	}
+
+	for _, tt := range tests {
+
+		samples := make([]sample, len(tt.weights))
+		for i, w := range tt.weights {
+			samples[i].weight = w
+		}
+
+		if got := medianIndex(samples); got != tt.want {
+			t.Errorf("%s: medianIndex() = %d, want %d", tt.name, got, tt.want)
+		}
+
+	}
+
+}